@@ -1,5 +1,9 @@
-import { resolve } from "node:path";
+import { tmpdir } from "node:os";
+import { dirname, resolve } from "node:path";
 import { type KeyEvent, createCliRenderer } from "@opentui/core";
+import { type CliCommand, CliUsageError, USAGE, parseCliArgs } from "./cli";
+import { copyToClipboard, getLogExportPath } from "./clipboard";
+import { getDesiredStatePath } from "./desired-state";
 import { DockerManager, detectComposeFile } from "./docker";
 import { FocusManager } from "./focus";
 import {
@@ -7,16 +11,38 @@ import {
   detectServices,
   finalizeSelection,
   formatServiceSummary,
+  formatStrategySummary,
+  listStrategies,
   writeManifest,
 } from "./init";
-import { loadManifest, parseServiceBlock, renderServiceBlock, saveManifest } from "./manifest";
+import { createInterpolationContext } from "./interpolate";
+import {
+  getManifestVars,
+  isActiveInProfile,
+  loadManifest,
+  parseServiceBlock,
+  renderServiceBlock,
+  saveManifest,
+  setServiceEnabled,
+} from "./manifest";
+import { watchManifest } from "./manifest-watcher";
+import { parseListenAddress, startMetricsServer } from "./metrics";
 import { cleanupExistingPids, syncPidFiles } from "./pidfile";
 import { getTopologicalServiceOrder } from "./service-graph";
+import { runInServiceEnv } from "./service";
 import { ServiceManager } from "./service-manager";
-import { fileExists, getErrorMessage } from "./shared";
+import {
+  fileExists,
+  formatCommandSpec,
+  getErrorMessage,
+  parseDuration,
+  parseSignalName,
+} from "./shared";
 import { createShutdownHandler } from "./shutdown";
-import type { AppConfig, PanelId, Shortcut } from "./types";
+import type { AppConfig, PanelId, ServiceConfig, Shortcut } from "./types";
 import { type UiControls, buildInitUi, buildUi } from "./ui";
+import { loadUserConfig, mergeAppConfig } from "./user-config";
+import { findUnresolvableCommands } from "./validate";
 
 const MANIFEST_PATH = "stasium.toml";
 
@@ -40,12 +66,29 @@ type MainUiSession = {
   dockerManager: DockerManager | null;
 };
 
+type ManifestActions = {
+  save: () => Promise<void>;
+  disable: (config: ServiceConfig) => Promise<void>;
+  disabledNames: () => string[];
+  isIncluded: (name: string) => boolean;
+  // Resolves true when the service joined the running set, false when its profile keeps it out.
+  enable: (name: string) => Promise<boolean>;
+  reload: () => Promise<void>;
+  confirmStop: () => boolean;
+};
+
 type MainUiSnapshot = {
   activePanel: PanelId;
   visiblePanels: PanelId[];
   logsFollowTail: boolean;
 };
 
+// Spells a keypress the way shortcut lists do: "s", "S" for shift+s, or the typed character.
+const pressedKey = (key: KeyEvent): string => {
+  if (key.ctrl || key.meta) return "";
+  return /^[a-z]$/.test(key.name) ? (key.shift ? key.name.toUpperCase() : key.name) : key.sequence;
+};
+
 const setupInitSelectionKeybindings = (
   renderer: Awaited<ReturnType<typeof createCliRenderer>>,
   getSelection: () => DiscoverySelection,
@@ -106,12 +149,11 @@ const setupKeybindings = (
   focusManager: FocusManager,
   dockerManager: DockerManager | null,
   controls: UiControls,
-  manifestPath: string,
-  appConfig: AppConfig | undefined,
+  manifestActions: ManifestActions,
   runtime: AppRuntime,
   shutdown: ShutdownController,
 ) => {
-  let deleteConfirming = false;
+  let pendingConfirm: (() => Promise<void>) | null = null;
   let discoverySelection: DiscoverySelection | null = null;
   let discoveryOpening = false;
   let discoveryApplying = false;
@@ -122,6 +164,219 @@ const setupKeybindings = (
     });
   };
 
+  // Starts can wait on slow oneshot dependencies, so overlays save and close before them and
+  // a failure shows up in the footer instead.
+  const startInBackground = (names: string[]) => {
+    void (async () => {
+      for (const name of names) {
+        await manager.startNamed(name);
+      }
+      await syncPids();
+    })().catch((error) => {
+      controls.showNotice(getErrorMessage(error), true);
+    });
+  };
+
+  const requestConfirm = (title: string, message: string, action: () => Promise<void>) => {
+    pendingConfirm = action;
+    controls.showConfirm(title, message);
+  };
+
+  // Deletes always ask first; stops only while [app] confirm_stop is left on.
+  const confirmStop = async (message: string, action: () => Promise<void>) => {
+    if (!manifestActions.confirmStop()) {
+      await action();
+      return;
+    }
+    requestConfirm("Stop", message, action);
+  };
+
+  const stopSelected = async () => {
+    const view = manager.getSelectedView();
+    if (!view) return;
+    await confirmStop(`Stop ${view.name}?`, () => manager.stopSelected());
+  };
+
+  const stopAll = () => confirmStop("Stop all services?", () => manager.stopAll());
+
+  const restartAll = () => confirmStop("Restart all services?", () => manager.restartAll());
+
+  // Removing an included service from this file would not stick: the next load brings it back.
+  const deleteSelected = () => {
+    const view = manager.getSelectedView();
+    if (!view) return;
+    if (manifestActions.isIncluded(view.name)) {
+      controls.showNotice(
+        `cannot delete ${view.name}: it comes from an included file, remove it there`,
+        true,
+      );
+      return;
+    }
+    requestConfirm("Delete service", `Delete "${view.name}"?`, async () => {
+      await manager.removeSelected();
+      await manifestActions.save();
+      await syncPids();
+    });
+  };
+
+  // Disabling stops the service and keeps it in the manifest with enabled = false.
+  const disableSelected = () => {
+    const config = manager.getSelectedConfig();
+    if (!config) return;
+    const dependents = manager
+      .getConfigs()
+      .filter((entry) => entry.depends_on?.includes(config.name))
+      .map((entry) => entry.name);
+    if (dependents.length > 0) {
+      controls.showNotice(
+        `cannot disable ${config.name}: ${dependents.join(", ")} depend on it`,
+        true,
+      );
+      return;
+    }
+    requestConfirm("Disable service", `Disable "${config.name}"?`, async () => {
+      await manager.removeSelected();
+      await manifestActions.disable(config);
+      await syncPids();
+    });
+  };
+
+  const stopSelectedDocker = async () => {
+    const service = dockerManager?.getSelectedService();
+    if (!dockerManager || !service) return;
+    await confirmStop(`Stop ${service.name}?`, () => dockerManager.stopSelected());
+  };
+
+  // Docker failures are expected (no container, compose errors), so they go to the footer.
+  const runDockerAction = async (action: (docker: DockerManager) => Promise<void>) => {
+    if (!dockerManager) return;
+    try {
+      await action(dockerManager);
+    } catch (error) {
+      controls.showNotice(getErrorMessage(error), true);
+    }
+  };
+
+  const removeSelectedDocker = () => {
+    const service = dockerManager?.getSelectedService();
+    if (!service) return;
+    requestConfirm("Remove container", `Remove the ${service.name} container?`, () =>
+      runDockerAction((docker) => docker.removeSelected()),
+    );
+  };
+
+  const reloadSelected = () => {
+    try {
+      manager.reloadSelected();
+    } catch (error) {
+      controls.showNotice(getErrorMessage(error), true);
+    }
+  };
+
+  const openSignalPrompt = () => {
+    const view = manager.getSelectedView();
+    if (!view) return;
+    focusManager.setMode("signaling");
+    controls.showSignalPrompt(view.name);
+  };
+
+  const openEnablePrompt = () => {
+    const names = manifestActions.disabledNames();
+    if (names.length === 0) {
+      controls.showNotice("no disabled services");
+      return;
+    }
+    focusManager.setMode("enabling");
+    controls.showEnablePrompt(names);
+  };
+
+  // Enabling saves and closes first; the start runs afterwards, like a newly added service.
+  const handleEnabling = async (key: KeyEvent) => {
+    if (key.name === "enter" || key.name === "return") {
+      const name = controls.getEnableInput().trim();
+      try {
+        const added = await manifestActions.enable(name);
+        controls.hideEnablePrompt();
+        focusManager.setMode("normal");
+        if (added) {
+          startInBackground([name]);
+        } else {
+          controls.showNotice(`enabled ${name}, outside the selected profile`);
+        }
+      } catch (error) {
+        controls.setEnableError(getErrorMessage(error));
+      }
+      return;
+    }
+
+    if (key.name === "escape") {
+      controls.hideEnablePrompt();
+      focusManager.setMode("normal");
+      return;
+    }
+  };
+
+  const handleSignaling = (key: KeyEvent) => {
+    if (key.name === "enter" || key.name === "return") {
+      const input = controls.getSignalInput();
+      const signal = parseSignalName(input);
+      if (!signal) {
+        controls.setSignalError(`Unknown signal: ${input.trim() || "(empty)"}`);
+        return;
+      }
+
+      try {
+        manager.signalSelected(signal);
+        controls.hideSignalPrompt();
+        focusManager.setMode("normal");
+      } catch (error) {
+        controls.setSignalError(getErrorMessage(error));
+      }
+      return;
+    }
+
+    if (key.name === "escape") {
+      controls.hideSignalPrompt();
+      focusManager.setMode("normal");
+      return;
+    }
+  };
+
+  const copyText = (label: string, text: string) => {
+    copyToClipboard(text);
+    controls.showNotice(`copied ${label}`);
+  };
+
+  const copySelectedCommand = () => {
+    const config = manager.getSelectedConfig();
+    if (!config) return;
+    copyText(`${config.name} command`, formatCommandSpec(config.command));
+  };
+
+  const copySelectedDockerName = () => {
+    const service = dockerManager?.getSelectedService();
+    if (!service) return;
+    copyText(`${service.name} name`, service.name);
+  };
+
+  const saveLogs = async () => {
+    const logs = controls.getActiveLogExport();
+    if (!logs) return;
+    const path = getLogExportPath(tmpdir(), logs.name, new Date());
+    try {
+      await Bun.write(path, `${logs.text}\n`);
+      controls.showNotice(`logs saved to ${path}`);
+    } catch (error) {
+      controls.showNotice(`log save failed: ${getErrorMessage(error)}`, true);
+    }
+  };
+
+  const copyLogs = () => {
+    const logs = controls.getActiveLogExport();
+    if (!logs) return;
+    copyText(`${logs.name} logs`, logs.text);
+  };
+
   const closeDiscovery = () => {
     discoveryApplying = false;
     discoverySelection = null;
@@ -147,100 +402,61 @@ const setupKeybindings = (
   };
 
   const handleNormalManifest = async (key: KeyEvent) => {
-    switch (key.name) {
-      case "s":
-        await manager.startSelected();
-        break;
-      case "x":
-        await manager.stopSelected();
-        break;
-      case "r":
-        await manager.restartSelected();
-        break;
-      case "a":
-        focusManager.setMode("adding");
-        controls.showAddOverlay();
-        break;
-      case "i":
-        await openDiscovery();
-        break;
-      case "d": {
-        const view = manager.getSelectedView();
-        if (view) {
-          deleteConfirming = true;
-          controls.showDeleteConfirm(view.name);
-        }
-        break;
-      }
-      case "e": {
-        const config = manager.getSelectedConfig();
-        if (config) {
-          const toml = renderServiceBlock(config);
-          focusManager.setMode("editing");
-          controls.showEditOverlay(toml);
-        }
-        break;
-      }
-      case "up":
-        manager.moveSelection(-1);
-        break;
-      case "down":
-        manager.moveSelection(1);
-        break;
-      default:
-        break;
+    if (key.name === "up" || key.name === "down") {
+      manager.moveSelection(key.name === "up" ? -1 : 1);
+      return;
     }
+
+    const shortcut = focusManager.findPanelShortcut(pressedKey(key));
+    if (shortcut) await triggerManifestShortcut(shortcut);
   };
 
-  const handleNormalLogs = (key: KeyEvent) => {
-    // Shift+G (capital G) = scroll to bottom, must check before lowercase g
-    if (key.name === "g" && key.shift) {
-      controls.scrollLogsToBottom();
+  const openLogSearch = () => {
+    focusManager.setMode("searching");
+    controls.showLogSearch();
+  };
+
+  const handleSearching = (key: KeyEvent) => {
+    if (key.name === "enter" || key.name === "return") {
+      controls.setLogSearch(controls.getLogSearchInput().trim());
+      controls.hideLogSearch();
+      focusManager.setMode("normal");
+      controls.moveLogMatch(-1);
       return;
     }
 
-    switch (key.name) {
-      case "up":
-        controls.moveLogSelection(-1);
-        break;
-      case "down":
-        controls.moveLogSelection(1);
-        break;
-      case "g":
-        controls.scrollLogsToTop();
-        break;
-      case "c":
-        controls.clearLogs();
-        break;
-      case "f":
-        controls.toggleLogsFollowTail();
-        break;
-      default:
-        break;
+    if (key.name === "escape") {
+      controls.setLogSearch("");
+      controls.hideLogSearch();
+      focusManager.setMode("normal");
+      return;
     }
   };
 
+  const handleNormalLogs = async (key: KeyEvent) => {
+    if (key.name === "up" || key.name === "down") {
+      controls.moveLogSelection(key.name === "up" ? -1 : 1);
+      return;
+    }
+
+    if (key.name === "n" && !key.ctrl && !key.meta) {
+      controls.moveLogMatch(key.shift ? -1 : 1);
+      return;
+    }
+
+    const shortcut = focusManager.findPanelShortcut(pressedKey(key));
+    if (shortcut) await triggerLogsShortcut(shortcut);
+  };
+
   const handleNormalDocker = async (key: KeyEvent) => {
     if (!dockerManager) return;
-    switch (key.name) {
-      case "s":
-        await dockerManager.startSelected();
-        break;
-      case "x":
-        await dockerManager.stopSelected();
-        break;
-      case "r":
-        await dockerManager.restartSelected();
-        break;
-      case "up":
-        dockerManager.moveSelection(-1);
-        break;
-      case "down":
-        dockerManager.moveSelection(1);
-        break;
-      default:
-        break;
+    if (key.name === "up" || key.name === "down") {
+      dockerManager.moveSelection(key.name === "up" ? -1 : 1);
+      return;
     }
+
+    const shortcut = focusManager.findPanelShortcut(pressedKey(key));
+    if (shortcut) await triggerDockerShortcut(shortcut);
   };
 
   const handleEditing = async (key: KeyEvent) => {
@@ -251,7 +467,7 @@ const setupKeybindings = (
         const config = parseServiceBlock(toml);
         const index = manager.getSelectedIndex();
         await manager.updateServiceConfig(index, config);
-        await saveManifest(manifestPath, manager.getConfigs(), appConfig);
+        await manifestActions.save();
         await syncPids();
       } catch (error) {
         controls.setEditError(getErrorMessage(error));
@@ -259,6 +475,7 @@ const setupKeybindings = (
       }
       controls.hideEditOverlay();
       focusManager.setMode("normal");
+      startInBackground([config.name]);
       return;
     }
 
@@ -281,10 +498,10 @@ const setupKeybindings = (
 
       try {
         await manager.addService({ name, command });
-        await saveManifest(manifestPath, manager.getConfigs(), appConfig);
-        await syncPids();
+        await manifestActions.save();
         controls.hideAddOverlay();
         focusManager.setMode("normal");
+        startInBackground([name]);
       } catch (error) {
         controls.setAddError(getErrorMessage(error));
       }
@@ -355,20 +572,20 @@ const setupKeybindings = (
             ...finalized.services,
           ]);
 
-          for (const serviceName of orderedNames) {
+          const addedNames = orderedNames.filter((serviceName) => pendingByName.has(serviceName));
+          for (const serviceName of addedNames) {
             const service = pendingByName.get(serviceName);
-            if (!service) continue;
-            await manager.addService(service);
+            if (service) await manager.addService(service);
           }
 
-          await saveManifest(manifestPath, manager.getConfigs(), appConfig);
-          await syncPids();
+          await manifestActions.save();
 
           for (const warning of finalized.warnings) {
             console.error(`Discovery warning: ${warning}`);
           }
 
           closeDiscovery();
+          startInBackground(addedNames);
         } catch (error) {
           controls.setDiscoveryError(getErrorMessage(error));
         } finally {
@@ -381,19 +598,20 @@ const setupKeybindings = (
     }
   };
 
-  const handleDeleteConfirm = async (key: KeyEvent) => {
+  const handleConfirm = async (key: KeyEvent) => {
+    const action = pendingConfirm;
+    if (!action) return;
+
     if (key.name === "y") {
-      await manager.removeSelected();
-      await saveManifest(manifestPath, manager.getConfigs(), appConfig);
-      await syncPids();
-      deleteConfirming = false;
-      controls.hideDeleteConfirm();
+      pendingConfirm = null;
+      controls.hideConfirm();
+      await action();
       return;
     }
 
     if (key.name === "n" || key.name === "escape") {
-      deleteConfirming = false;
-      controls.hideDeleteConfirm();
+      pendingConfirm = null;
+      controls.hideConfirm();
       return;
     }
   };
@@ -404,11 +622,20 @@ const setupKeybindings = (
         await manager.startSelected();
         return;
       case "stop":
-        await manager.stopSelected();
+        await stopSelected();
         return;
       case "restart":
         await manager.restartSelected();
         return;
+      case "start all":
+        await manager.startAll();
+        return;
+      case "stop all":
+        await stopAll();
+        return;
+      case "restart all":
+        await restartAll();
+        return;
       case "add":
         focusManager.setMode("adding");
         controls.showAddOverlay();
@@ -416,13 +643,15 @@ const setupKeybindings = (
       case "discover":
         await openDiscovery();
         return;
-      case "delete": {
-        const view = manager.getSelectedView();
-        if (!view) return;
-        deleteConfirming = true;
-        controls.showDeleteConfirm(view.name);
+      case "delete":
+        deleteSelected();
+        return;
+      case "disable":
+        disableSelected();
+        return;
+      case "enable":
+        openEnablePrompt();
         return;
-      }
       case "edit": {
         const config = manager.getSelectedConfig();
         if (!config) return;
@@ -430,6 +659,15 @@ const setupKeybindings = (
         controls.showEditOverlay(renderServiceBlock(config));
         return;
       }
+      case "reload svc":
+        reloadSelected();
+        return;
+      case "signal":
+        openSignalPrompt();
+        return;
+      case "copy":
+        copySelectedCommand();
+        return;
       case "select":
         manager.moveSelection(1);
         return;
@@ -455,6 +693,27 @@ const setupKeybindings = (
       case "clear":
         controls.clearLogs();
         return;
+      case "search":
+        openLogSearch();
+        return;
+      case "next match":
+        controls.moveLogMatch(1);
+        return;
+      case "stream":
+        controls.cycleLogStreamFilter();
+        return;
+      case "matches only":
+        controls.toggleLogMatchesOnly();
+        return;
+      case "time":
+        controls.toggleLogTimestamps();
+        return;
+      case "save logs":
+        await saveLogs();
+        return;
+      case "copy":
+        copyLogs();
+        return;
       default:
         return;
     }
@@ -467,11 +726,26 @@ const setupKeybindings = (
         await dockerManager.startSelected();
         return;
       case "stop":
-        await dockerManager.stopSelected();
+        await stopSelectedDocker();
         return;
       case "restart":
         await dockerManager.restartSelected();
         return;
+      case "start all":
+        await dockerManager.startAll();
+        return;
+      case "pause":
+        await runDockerAction((docker) => docker.pauseSelected());
+        return;
+      case "unpause":
+        await runDockerAction((docker) => docker.unpauseSelected());
+        return;
+      case "remove":
+        removeSelectedDocker();
+        return;
+      case "copy":
+        copySelectedDockerName();
+        return;
       case "select":
         dockerManager.moveSelection(1);
         return;
@@ -480,8 +754,22 @@ const setupKeybindings = (
     }
   };
 
+  const openHelp = () => {
+    focusManager.setMode("help");
+    controls.showHelp(focusManager.getHelpSections());
+  };
+
+  const closeHelp = () => {
+    controls.hideHelp();
+    focusManager.setMode("normal");
+  };
+
   const triggerShortcut = async (shortcut: Shortcut): Promise<void> => {
-    if (focusManager.getMode() !== "normal" || deleteConfirming) return;
+    if (focusManager.getMode() === "help" && shortcut.label === "close") {
+      closeHelp();
+      return;
+    }
+    if (focusManager.getMode() !== "normal" || pendingConfirm) return;
 
     switch (shortcut.label) {
       case "manifest panel":
@@ -502,6 +790,12 @@ const setupKeybindings = (
       case "quit":
         await handleQuit("User requested shutdown.");
         return;
+      case "reload":
+        await manifestActions.reload();
+        return;
+      case "help":
+        openHelp();
+        return;
       case "log page":
         if (controls.isLogsPanelVisible()) controls.scrollLogsPage(1);
         return;
@@ -605,9 +899,29 @@ const setupKeybindings = (
         return;
       }
 
+      if (mode === "searching") {
+        handleSearching(key);
+        return;
+      }
+
+      if (mode === "signaling") {
+        handleSignaling(key);
+        return;
+      }
+
+      if (mode === "enabling") {
+        await handleEnabling(key);
+        return;
+      }
+
+      if (mode === "help") {
+        if (key.name === "escape" || key.sequence === "?") closeHelp();
+        return;
+      }
+
       // Normal mode
-      if (deleteConfirming) {
-        await handleDeleteConfirm(key);
+      if (pendingConfirm) {
+        await handleConfirm(key);
         return;
       }
 
@@ -621,6 +935,16 @@ const setupKeybindings = (
         return;
       }
 
+      if (key.ctrl && key.name === "r") {
+        await manifestActions.reload();
+        return;
+      }
+
+      if (key.sequence === "?") {
+        openHelp();
+        return;
+      }
+
       if (key.name === "q" || key.name === "escape") {
         await handleQuit("User requested shutdown.");
         return;
@@ -657,7 +981,7 @@ const setupKeybindings = (
       }
 
       if (panel === "logs") {
-        handleNormalLogs(key);
+        await handleNormalLogs(key);
         return;
       }
 
@@ -701,19 +1025,22 @@ const restoreMainUiSnapshot = (
   controls.renderAll();
 };
 
+// One [app] poll_interval drives both the docker refresh and resource sampling.
+const getPollIntervalMs = (app?: AppConfig): number | undefined =>
+  app?.poll_interval === undefined ? undefined : (parseDuration(app.poll_interval) ?? undefined);
+
 const mountMainUiSession = (
   renderer: Awaited<ReturnType<typeof createCliRenderer>>,
   teardownRef: { current: (() => void) | null },
   runtime: AppRuntime,
   shutdown: ShutdownController,
-  manifestPath: string,
+  manifestActions: ManifestActions,
   manager: ServiceManager,
   manifest: Awaited<ReturnType<typeof loadManifest>>,
-  appConfig: AppConfig | undefined,
   dockerManager: DockerManager | null,
   snapshot?: MainUiSnapshot,
 ): MainUiSession => {
-  const focusManager = new FocusManager(dockerManager !== null);
+  const focusManager = new FocusManager(dockerManager !== null, manifest.app?.keys);
   const { teardown, controls } = buildUi({
     renderer,
     manifest,
@@ -732,8 +1059,7 @@ const mountMainUiSession = (
     focusManager,
     dockerManager,
     controls,
-    manifestPath,
-    appConfig,
+    manifestActions,
     runtime,
     shutdown,
   );
@@ -745,7 +1071,7 @@ const mountMainUiSession = (
   }
 
   if (dockerManager && !runtime.closing && !runtime.disposed) {
-    dockerManager.startPolling();
+    dockerManager.startPolling(getPollIntervalMs(manifest.app));
   }
 
   return {
@@ -761,17 +1087,138 @@ const startApp = async (
   teardownRef: { current: (() => void) | null },
   shutdownRef: { current: ShutdownController | null },
   runtime: AppRuntime,
+  profile?: string,
+  userApp?: AppConfig,
 ) => {
-  const manifest = await loadManifest(MANIFEST_PATH);
-  const manager = new ServiceManager(manifest.services);
-  const appConfig = manifest.app;
+  const manifest = await loadManifest(MANIFEST_PATH, profile);
+  const appConfig = mergeAppConfig(userApp, manifest.app);
+  const manager = new ServiceManager(manifest.services, {
+    interpolation: createInterpolationContext(dirname(manifest.path), getManifestVars(manifest)),
+    procRoot: process.env.STASIUM_PROC_ROOT || undefined,
+    logLines: appConfig?.log_lines,
+    resourceHistory: appConfig?.resource_history,
+    desiredStatePath: getDesiredStatePath(process.cwd()),
+    logger: (message) => console.error(message),
+  });
   const manifestPath = resolve(process.cwd(), MANIFEST_PATH);
 
+  const manifestState = {
+    app: manifest.app,
+    vars: manifest.vars,
+    includes: manifest.includes,
+    profiles: manifest.profiles,
+    inactiveServices: manifest.inactiveServices,
+    serviceOrder: manifest.serviceOrder,
+  };
+  const manifestReloads = { ok: 0, failed: 0 };
+  let reloadChain = Promise.resolve();
+
+  // A manifest that fails to load or validate leaves the running services untouched.
+  const applyManifestReload = async () => {
+    try {
+      const next = await loadManifest(manifestPath, profile);
+      for (const warning of next.warnings) {
+        console.error(`Manifest warning: ${warning}`);
+      }
+
+      await manager.reload(
+        next.services,
+        createInterpolationContext(dirname(next.path), getManifestVars(next)),
+      );
+      manifestState.app = next.app;
+      manifestState.vars = next.vars;
+      manifestState.includes = next.includes;
+      manifestState.profiles = next.profiles;
+      manifestState.inactiveServices = next.inactiveServices;
+      manifestState.serviceOrder = next.serviceOrder;
+      manifestReloads.ok += 1;
+      await syncCurrentPids();
+    } catch (error) {
+      manifestReloads.failed += 1;
+      console.error(`Manifest reload failed, keeping current config: ${getErrorMessage(error)}`);
+    }
+  };
+
+  const manifestWatcher = watchManifest(manifestPath, await Bun.file(manifestPath).text(), () =>
+    manifestActions.reload(),
+  );
+
+  const manifestActions: ManifestActions = {
+    save: async () => {
+      const contents = await saveManifest(
+        manifestPath,
+        manager.getConfigs(),
+        manifestState.app,
+        manifestState.vars,
+        {
+          includes: manifestState.includes,
+          profiles: manifestState.profiles,
+          inactiveServices: manifestState.inactiveServices,
+          order: manifestState.serviceOrder,
+        },
+      );
+      manifestWatcher.markWritten(contents);
+    },
+    disable: async (config) => {
+      manifestState.inactiveServices = [
+        ...(manifestState.inactiveServices ?? []),
+        { ...config, enabled: false },
+      ];
+      await manifestActions.save();
+    },
+    isIncluded: (name) => manifestState.includes?.names.includes(name) ?? false,
+    disabledNames: () =>
+      (manifestState.inactiveServices ?? [])
+        .filter((config) => config.enabled === false)
+        .map((config) => config.name),
+    enable: async (name) => {
+      const inactive = manifestState.inactiveServices ?? [];
+      const config = inactive.find((entry) => entry.name === name && entry.enabled === false);
+      if (!config) throw new Error(`no disabled service named ${name || "(empty)"}`);
+
+      const enabled = { ...config, enabled: undefined };
+      const active = isActiveInProfile(enabled, profile);
+      if (active) await manager.addService(enabled);
+      const rest = inactive.filter((entry) => entry !== config);
+      manifestState.inactiveServices = active ? rest : [...rest, enabled];
+      await manifestActions.save();
+      return active;
+    },
+    confirmStop: () => mergeAppConfig(userApp, manifestState.app)?.confirm_stop ?? true,
+    // Reloads are serialized so a keypress and a file change cannot interleave.
+    reload: () => {
+      reloadChain = reloadChain.then(applyManifestReload);
+      return reloadChain;
+    },
+  };
+
+  // The listen address is read once at startup; changing it needs a restart.
+  const metricsAddress = appConfig?.metrics ? parseListenAddress(appConfig.metrics) : null;
+  let metricsServer: { stop: () => void } | null = null;
+  if (metricsAddress) {
+    const startedAt = Date.now();
+    try {
+      metricsServer = startMetricsServer(metricsAddress, () => ({
+        services: manager.getViews(),
+        actions: manager.getActionCounts(),
+        manifestReloads,
+        uptimeSeconds: (Date.now() - startedAt) / 1000,
+      }));
+    } catch (error) {
+      console.error(`Metrics server failed to start: ${getErrorMessage(error)}`);
+    }
+  }
+
   shutdownRef.current?.uninstall();
   const shutdown = createShutdownHandler({
     cwd: process.cwd(),
     manager,
     getServicePids: () => manager.getServicePids(),
+    onAfter: () => {
+      manager.stopResourceSampling();
+      manifestWatcher.close();
+      metricsServer?.stop();
+    },
     logger: (message) => console.error(message),
   });
   shutdown.install();
@@ -787,6 +1234,11 @@ const startApp = async (
   manager.onProcessChange(() => {
     void syncCurrentPids();
   });
+  manager.startResourceSampling(getPollIntervalMs(appConfig));
+
+  for (const warning of manifest.warnings) {
+    console.error(`Manifest warning: ${warning}`);
+  }
 
   const sessionRef: { current: MainUiSession | null } = {
     current: mountMainUiSession(
@@ -794,10 +1246,9 @@ const startApp = async (
       teardownRef,
       runtime,
       shutdown,
-      manifestPath,
+      manifestActions,
       manager,
-      manifest,
-      appConfig,
+      { ...manifest, app: appConfig },
       null,
     ),
   };
@@ -812,6 +1263,7 @@ const startApp = async (
 
       await manager.startAll({
         shouldCancel: () => runtime.closing || runtime.disposed,
+        resume: true,
       });
       if (runtime.closing || runtime.disposed) return;
 
@@ -830,7 +1282,10 @@ const startApp = async (
       }
       if (runtime.closing || runtime.disposed) return;
 
-      const dockerManager = new DockerManager(composePath);
+      const dockerManager = new DockerManager(
+        composePath,
+        mergeAppConfig(userApp, manifestState.app)?.log_lines,
+      );
       if (runtime.closing || runtime.disposed) {
         await dockerManager.destroy();
         return;
@@ -843,10 +1298,9 @@ const startApp = async (
         teardownRef,
         runtime,
         shutdown,
-        manifestPath,
+        manifestActions,
         manager,
-        manifest,
-        appConfig,
+        { ...manifest, app: appConfig },
         dockerManager,
         snapshot,
       );
@@ -918,7 +1372,24 @@ const startInitFlow = (
 };
 
 export const run = async () => {
-  const args = process.argv.slice(2);
+  let command: CliCommand;
+  try {
+    command = parseCliArgs(process.argv.slice(2));
+  } catch (error) {
+    if (!(error instanceof CliUsageError)) throw error;
+    console.error(`${error.message}\n\n${USAGE}`);
+    process.exitCode = 2;
+    return;
+  }
+
+  if (command.name === "help") {
+    console.log(USAGE);
+    return;
+  }
+
+  const profile =
+    ("profile" in command ? command.profile : undefined) ??
+    (process.env.STASIUM_PROFILE || undefined);
   const hasManifest = await fileExists(MANIFEST_PATH);
   const teardownRef: { current: (() => void) | null } = { current: null };
   const shutdownRef: { current: ShutdownController | null } = { current: null };
@@ -929,7 +1400,64 @@ export const run = async () => {
     exitCode: null,
   };
 
-  if (args[0] === "init") {
+  if (command.name === "validate") {
+    await loadUserConfig();
+    const manifest = await loadManifest(MANIFEST_PATH, profile);
+    for (const warning of manifest.warnings) {
+      console.error(`Manifest warning: ${warning}`);
+    }
+
+    const problems = command.strict ? await findUnresolvableCommands(manifest) : [];
+    if (problems.length > 0) {
+      for (const problem of problems) {
+        console.error(problem);
+      }
+      process.exitCode = 1;
+      return;
+    }
+
+    console.log(`Manifest OK: ${manifest.path}`);
+    return;
+  }
+
+  if (command.name === "exec") {
+    const manifest = await loadManifest(MANIFEST_PATH, profile);
+    // Disabled and profile-excluded services still have an environment to run a command in.
+    const config = [...manifest.services, ...(manifest.inactiveServices ?? [])].find(
+      (service) => service.name === command.service,
+    );
+    if (!config) {
+      console.error(`unknown service: ${command.service}`);
+      process.exitCode = 1;
+      return;
+    }
+    process.exitCode = await runInServiceEnv(
+      config,
+      command.command,
+      createInterpolationContext(dirname(manifest.path), getManifestVars(manifest)),
+    );
+    return;
+  }
+
+  // A running stasium picks the change up through its manifest watcher.
+  if (command.name === "enable" || command.name === "disable") {
+    await setServiceEnabled(MANIFEST_PATH, command.service, command.name === "enable");
+    console.log(`${command.name === "enable" ? "Enabled" : "Disabled"} ${command.service}`);
+    return;
+  }
+
+  if (command.name === "init" && command.list) {
+    const { strategies, warnings } = await listStrategies(process.cwd());
+    for (const strategy of strategies) {
+      console.log(formatStrategySummary(strategy));
+    }
+    for (const warning of warnings) {
+      console.error(`Discovery warning: ${warning}`);
+    }
+    return;
+  }
+
+  if (command.name === "init") {
     const manifestPath = resolve(process.cwd(), MANIFEST_PATH);
     if (hasManifest) {
       console.error(`Manifest already exists: ${manifestPath}`);
@@ -989,6 +1517,7 @@ export const run = async () => {
     return;
   }
 
+  const userApp = await loadUserConfig();
   const renderer = await createCliRenderer({
     exitOnCtrlC: false,
     useMouse: true,
@@ -1029,7 +1558,7 @@ export const run = async () => {
   });
 
   if (hasManifest) {
-    await startApp(renderer, teardownRef, shutdownRef, runtime);
+    await startApp(renderer, teardownRef, shutdownRef, runtime, profile, userApp);
     renderer.start();
     return;
   }
@@ -1041,7 +1570,7 @@ export const run = async () => {
       teardownRef.current?.();
       teardownRef.current = null;
       await writeManifest(manifestPath, finalized.services);
-      await startApp(renderer, teardownRef, shutdownRef, runtime);
+      await startApp(renderer, teardownRef, shutdownRef, runtime, undefined, userApp);
     } catch (error) {
       console.error(getErrorMessage(error));
       process.exitCode = 1;
@@ -0,0 +1,57 @@
+import { describe, expect, test } from "bun:test";
+import { CliUsageError, parseCliArgs } from "./cli";
+
+describe("cli arguments", () => {
+  test("defaults to running the manifest", () => {
+    expect(parseCliArgs([])).toEqual({ name: "run" });
+  });
+
+  test("accepts command flags in any position", () => {
+    expect(parseCliArgs(["validate", "--strict"])).toEqual({ name: "validate", strict: true });
+    expect(parseCliArgs(["--strict", "validate"])).toEqual({ name: "validate", strict: true });
+    expect(parseCliArgs(["init"])).toEqual({ name: "init", list: false });
+    expect(parseCliArgs(["--list", "init"])).toEqual({ name: "init", list: true });
+  });
+
+  test("shows help for --help anywhere", () => {
+    expect(parseCliArgs(["init", "--help"])).toEqual({ name: "help" });
+    expect(parseCliArgs(["-h"])).toEqual({ name: "help" });
+  });
+
+  test("rejects unknown commands, stray arguments and foreign flags", () => {
+    expect(() => parseCliArgs(["serve"])).toThrow("unknown command: serve");
+    expect(() => parseCliArgs(["init", "extra"])).toThrow("unexpected argument: extra");
+    expect(() => parseCliArgs(["init", "--strict"])).toThrow("unknown option for init: --strict");
+    expect(() => parseCliArgs(["--list"])).toThrow(CliUsageError);
+  });
+
+  test("reads a profile name for run and validate", () => {
+    expect(parseCliArgs(["--profile", "test"])).toEqual({ name: "run", profile: "test" });
+    expect(parseCliArgs(["validate", "--profile=ci"])).toEqual({
+      name: "validate",
+      strict: false,
+      profile: "ci",
+    });
+    expect(() => parseCliArgs(["--profile"])).toThrow("--profile needs a profile name");
+    expect(() => parseCliArgs(["init", "--profile", "test"])).toThrow(
+      "unknown option for init: --profile",
+    );
+  });
+
+  test("takes a service name for enable and disable", () => {
+    expect(parseCliArgs(["disable", "worker"])).toEqual({ name: "disable", service: "worker" });
+    expect(parseCliArgs(["enable", "worker"])).toEqual({ name: "enable", service: "worker" });
+    expect(() => parseCliArgs(["enable"])).toThrow("enable needs a service name");
+    expect(() => parseCliArgs(["disable", "a", "b"])).toThrow("unexpected argument: b");
+  });
+
+  test("passes everything after -- to exec", () => {
+    expect(parseCliArgs(["exec", "api", "--", "php", "artisan", "migrate", "--force"])).toEqual({
+      name: "exec",
+      service: "api",
+      command: ["php", "artisan", "migrate", "--force"],
+    });
+    expect(() => parseCliArgs(["exec", "api"])).toThrow("exec needs a command after --");
+    expect(() => parseCliArgs(["validate", "--", "ls"])).toThrow("unexpected argument: --");
+  });
+});
@@ -0,0 +1,126 @@
+export type CliCommand =
+  | { name: "run"; profile?: string }
+  | { name: "help" }
+  | { name: "init"; list: boolean }
+  | { name: "validate"; strict: boolean; profile?: string }
+  | { name: "enable" | "disable"; service: string }
+  | { name: "exec"; service: string; command: string[]; profile?: string };
+
+export class CliUsageError extends Error {
+  constructor(message: string) {
+    super(message);
+    this.name = "CliUsageError";
+  }
+}
+
+export const USAGE = [
+  "Usage: stasium [command] [options]",
+  "",
+  "Commands:",
+  "  (none)               run the services in ./stasium.toml",
+  "  init [--list]        create stasium.toml from detected services, or list strategies",
+  "  validate [--strict]  check stasium.toml; --strict also checks that commands resolve",
+  "  enable <service>     let stasium run a disabled service again",
+  "  disable <service>    keep a service in stasium.toml without running it",
+  "  exec <service> -- <command...>",
+  "                       run a command with the service's working dir and env",
+  "  help                 show this message",
+  "",
+  "Options:",
+  "  --profile <name>     select a manifest profile for run, validate or exec",
+  "                       (defaults to $STASIUM_PROFILE)",
+  "  -h, --help           show this message",
+].join("\n");
+
+const COMMAND_FLAGS = new Map<string, string[]>([
+  ["help", []],
+  ["init", ["--list"]],
+  ["validate", ["--strict", "--profile"]],
+  ["enable", []],
+  ["disable", []],
+  ["exec", ["--profile"]],
+]);
+// Commands that take the name of a service as their one argument.
+const SERVICE_COMMANDS = new Set(["enable", "disable", "exec"]);
+const RUN_FLAGS = ["--profile"];
+
+// Pulls out `--profile <name>` or `--profile=<name>` so the value is not read as a command.
+const extractProfile = (args: string[]): { profile?: string; rest: string[] } => {
+  const rest: string[] = [];
+  let profile: string | undefined;
+  for (let index = 0; index < args.length; index += 1) {
+    const arg = args[index] ?? "";
+    if (arg !== "--profile" && !arg.startsWith("--profile=")) {
+      rest.push(arg);
+      continue;
+    }
+    const value = arg === "--profile" ? args[++index] : arg.slice("--profile=".length);
+    if (!value || value.startsWith("-")) {
+      throw new CliUsageError("--profile needs a profile name");
+    }
+    profile = value;
+    rest.push("--profile");
+  }
+  return { profile, rest };
+};
+
+// Flags may appear before or after the command; each command only accepts its own flags.
+export const parseCliArgs = (args: string[]): CliCommand => {
+  // Everything after `--` belongs to the command exec runs, flags included.
+  const separator = args.indexOf("--");
+  const trailing = separator === -1 ? [] : args.slice(separator + 1);
+  const ownArgs = separator === -1 ? args : args.slice(0, separator);
+  if (ownArgs.includes("--help") || ownArgs.includes("-h")) return { name: "help" };
+
+  const { profile, rest } = extractProfile(ownArgs);
+  const positionals = rest.filter((arg) => !arg.startsWith("-"));
+  const flags = rest.filter((arg) => arg.startsWith("-"));
+  const name = positionals[0];
+  const maxPositionals = name !== undefined && SERVICE_COMMANDS.has(name) ? 2 : 1;
+  if (positionals.length > maxPositionals) {
+    throw new CliUsageError(`unexpected argument: ${positionals[maxPositionals]}`);
+  }
+
+  const allowed = name === undefined ? RUN_FLAGS : COMMAND_FLAGS.get(name);
+  if (!allowed) {
+    throw new CliUsageError(`unknown command: ${name}`);
+  }
+
+  const unknown = flags.find((flag) => !allowed.includes(flag));
+  if (unknown) {
+    throw new CliUsageError(
+      name ? `unknown option for ${name}: ${unknown}` : `unknown option: ${unknown}`,
+    );
+  }
+
+  if (separator !== -1 && name !== "exec") {
+    throw new CliUsageError("unexpected argument: --");
+  }
+
+  switch (name) {
+    case "init":
+      return { name, list: flags.includes("--list") };
+    case "validate":
+      return profile === undefined
+        ? { name, strict: flags.includes("--strict") }
+        : { name, strict: flags.includes("--strict"), profile };
+    case "enable":
+    case "disable": {
+      const service = positionals[1];
+      if (!service) throw new CliUsageError(`${name} needs a service name`);
+      return { name, service };
+    }
+    case "exec": {
+      const service = positionals[1];
+      if (!service) throw new CliUsageError("exec needs a service name");
+      if (trailing.length === 0) throw new CliUsageError("exec needs a command after --");
+      return profile === undefined
+        ? { name, service, command: trailing }
+        : { name, service, command: trailing, profile };
+    }
+    case "help":
+      return { name };
+    default:
+      return profile === undefined ? { name: "run" } : { name: "run", profile };
+  }
+};
@@ -0,0 +1,22 @@
+import { describe, expect, test } from "bun:test";
+import { formatOsc52, getLogExportPath } from "./clipboard";
+
+describe("formatOsc52", () => {
+  test("wraps base64 text in an OSC 52 sequence", () => {
+    expect(formatOsc52("bun run dev")).toBe("\x1b]52;c;YnVuIHJ1biBkZXY=\x07");
+  });
+
+  test("keeps the tail of oversized payloads", () => {
+    const sequence = formatOsc52(`${"a".repeat(80_000)}end`);
+    const decoded = Buffer.from(sequence.slice(7, -1), "base64").toString("utf8");
+    expect(decoded.endsWith("end")).toBe(true);
+    expect(decoded.length).toBeLessThan(80_000);
+  });
+});
+
+describe("getLogExportPath", () => {
+  test("builds a file name from the service name and time", () => {
+    const path = getLogExportPath("/tmp", "api server", new Date("2026-01-02T03:04:05.678Z"));
+    expect(path).toBe("/tmp/stasium-api-server-2026-01-02T03-04-05-678Z.log");
+  });
+});
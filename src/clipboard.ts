@@ -0,0 +1,22 @@
+import { join } from "node:path";
+
+// Most terminals drop OSC 52 payloads past ~100KB, so long copies keep only the tail.
+const OSC52_MAX_BYTES = 74_994;
+
+export const formatOsc52 = (text: string): string => {
+  let bytes = Buffer.from(text, "utf8");
+  if (bytes.length > OSC52_MAX_BYTES) {
+    bytes = bytes.subarray(bytes.length - OSC52_MAX_BYTES);
+  }
+  return `\x1b]52;c;${bytes.toString("base64")}\x07`;
+};
+
+export const copyToClipboard = (text: string): void => {
+  process.stdout.write(formatOsc52(text));
+};
+
+export const getLogExportPath = (dir: string, name: string, now: Date): string => {
+  const safeName = name.replace(/[^A-Za-z0-9._-]+/g, "-") || "logs";
+  const stamp = now.toISOString().replace(/[:.]/g, "-");
+  return join(dir, `stasium-${safeName}-${stamp}.log`);
+};
@@ -0,0 +1,35 @@
+import { describe, expect, test } from "bun:test";
+import { createHash } from "node:crypto";
+import { realpathSync } from "node:fs";
+import { mkdtemp, rm } from "node:fs/promises";
+import { tmpdir } from "node:os";
+import { join } from "node:path";
+import { getDesiredStatePath, loadDesiredStates } from "./desired-state";
+
+describe("desired state", () => {
+  test("resolves a per-project file under XDG_STATE_HOME", () => {
+    const cwd = process.cwd();
+    const key = createHash("md5").update(realpathSync(cwd)).digest("hex");
+
+    expect(getDesiredStatePath(cwd, { XDG_STATE_HOME: "/tmp/xdg" })).toBe(
+      `/tmp/xdg/stasium/${key}.json`,
+    );
+  });
+
+  test("treats missing or malformed files as nothing recorded", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-desired-"));
+    const path = join(dir, "state.json");
+
+    try {
+      expect(await loadDesiredStates(path)).toEqual({});
+
+      await Bun.write(path, "not json");
+      expect(await loadDesiredStates(path)).toEqual({});
+
+      await Bun.write(path, JSON.stringify({ api: "stopped", db: "paused", web: "running" }));
+      expect(await loadDesiredStates(path)).toEqual({ api: "stopped", web: "running" });
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+});
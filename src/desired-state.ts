@@ -0,0 +1,47 @@
+import { mkdir } from "node:fs/promises";
+import { homedir } from "node:os";
+import { dirname, join } from "node:path";
+import { getProjectKey } from "./pidfile";
+
+export type DesiredState = "running" | "stopped";
+
+export type DesiredStates = Record<string, DesiredState>;
+
+const isDesiredState = (value: unknown): value is DesiredState =>
+  value === "running" || value === "stopped";
+
+// One file per project, keyed like the pid files, under the XDG state dir.
+export const getDesiredStatePath = (
+  cwd: string,
+  env: Record<string, string | undefined> = process.env,
+): string =>
+  join(
+    env.XDG_STATE_HOME || join(homedir(), ".local", "state"),
+    "stasium",
+    `${getProjectKey(cwd)}.json`,
+  );
+
+// A missing or unreadable file means nothing was recorded, so every service starts.
+export const loadDesiredStates = async (path: string): Promise<DesiredStates> => {
+  const file = Bun.file(path);
+  if (!(await file.exists())) return {};
+
+  let parsed: unknown;
+  try {
+    parsed = await file.json();
+  } catch {
+    return {};
+  }
+  if (typeof parsed !== "object" || parsed === null || Array.isArray(parsed)) return {};
+
+  const states: DesiredStates = {};
+  for (const [name, state] of Object.entries(parsed)) {
+    if (isDesiredState(state)) states[name] = state;
+  }
+  return states;
+};
+
+export const saveDesiredStates = async (path: string, states: DesiredStates): Promise<void> => {
+  await mkdir(dirname(path), { recursive: true });
+  await Bun.write(path, `${JSON.stringify(states, null, 2)}\n`);
+};
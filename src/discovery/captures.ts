@@ -38,6 +38,18 @@ const resolveJsonFirstExisting = async (
   return null;
 };
 
+const resolveJsonFirstExistingKey = async (
+  capture: Extract<StrategyCapture, { kind: "json_first_existing_key" }>,
+  ctx: DiscoveryProbeContext,
+): Promise<string | null> => {
+  for (const path of capture.paths) {
+    const raw = await ctx.getJsonPathValue(capture.file, path);
+    if (normalizeCapturedValue(raw) === null) continue;
+    return path.slice(path.lastIndexOf(".") + 1);
+  }
+  return null;
+};
+
 const resolveTomlFirstExisting = async (
   capture: Extract<StrategyCapture, { kind: "toml_first_existing" }>,
   ctx: DiscoveryProbeContext,
@@ -62,6 +74,10 @@ const resolveCapture = async (
     return resolveJsonFirstExisting(capture, ctx);
   }
 
+  if (capture.kind === "json_first_existing_key") {
+    return resolveJsonFirstExistingKey(capture, ctx);
+  }
+
   if (capture.kind === "toml_first_existing") {
     return resolveTomlFirstExisting(capture, ctx);
   }
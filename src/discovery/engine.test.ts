@@ -72,6 +72,50 @@ describe("discovery engine", () => {
     }
   });
 
+  test("captures the script name for the detected package manager", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-discovery-engine-"));
+
+    try {
+      await Bun.write(
+        join(dir, "package.json"),
+        JSON.stringify({ scripts: { start: "node server.js" } }, null, 2),
+      );
+      await Bun.write(join(dir, "package-lock.json"), "{}");
+
+      const strategy: DiscoveryStrategy = {
+        id: "node-dev",
+        label: "Node dev",
+        priority: 100,
+        default_selected: true,
+        when: {
+          ...emptyWhen(),
+          all_files: ["package.json"],
+        },
+        capture: [
+          {
+            name: "package_manager",
+            kind: "lockfile_package_manager",
+          },
+          {
+            name: "script",
+            kind: "json_first_existing_key",
+            file: "package.json",
+            paths: ["scripts.dev", "scripts.start"],
+          },
+        ],
+        service: {
+          name: "frontend",
+          command: ["${package_manager}", "run", "${script}"],
+        },
+      };
+
+      const detected = await detectDiscoveryCandidates(dir, [strategy]);
+      expect(detected.candidates[0]?.service.command).toEqual(["npm", "run", "start"]);
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
   test("skips candidates when required capture is missing", async () => {
     const dir = await mkdtemp(join(tmpdir(), "stasium-discovery-engine-"));
 
@@ -4,6 +4,7 @@ import builtinStrategiesToml from "./strategies.toml" with { type: "text" };
 import type {
   DiscoveryStrategy,
   JsonFirstExistingCapture,
+  JsonFirstExistingKeyCapture,
   LoadedStrategies,
   RegexProbe,
   StrategyCapture,
@@ -254,6 +255,18 @@ const parseCapture = (value: unknown, context: string): StrategyCapture => {
     return parsed;
   }
 
+  if (kind === "json_first_existing_key") {
+    const file = readString(capture.file, `${context}.file`);
+    const paths = readStringArray(capture.paths, `${context}.paths`);
+    const parsed: JsonFirstExistingKeyCapture = {
+      name,
+      kind,
+      file,
+      paths,
+    };
+    return parsed;
+  }
+
   if (kind === "toml_first_existing") {
     const file = readString(capture.file, `${context}.file`);
     const paths = readStringArray(capture.paths, `${context}.paths`);
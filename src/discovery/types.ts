@@ -37,6 +37,13 @@ export interface JsonFirstExistingCapture extends StrategyCaptureBase {
   paths: string[];
 }
 
+// Resolves to the final key of the first existing path, e.g. "dev" for "scripts.dev".
+export interface JsonFirstExistingKeyCapture extends StrategyCaptureBase {
+  kind: "json_first_existing_key";
+  file: string;
+  paths: string[];
+}
+
 export interface TomlFirstExistingCapture extends StrategyCaptureBase {
   kind: "toml_first_existing";
   file: string;
@@ -46,6 +53,7 @@ export interface TomlFirstExistingCapture extends StrategyCaptureBase {
 export type StrategyCapture =
   | LockfilePackageManagerCapture
   | JsonFirstExistingCapture
+  | JsonFirstExistingKeyCapture
   | TomlFirstExistingCapture;
 
 export interface StrategyServiceTemplate {
@@ -1,5 +1,10 @@
 import { describe, expect, test } from "bun:test";
-import { getStableDockerServiceNames } from "./docker";
+import {
+  DockerManager,
+  getStableDockerServiceNames,
+  isDockerStateEvent,
+  pickAggregateHealth,
+} from "./docker";
 
 describe("getStableDockerServiceNames", () => {
   test("sorts docker service names alphabetically and appends discovered extras", () => {
@@ -19,3 +24,38 @@ describe("getStableDockerServiceNames", () => {
     ]);
   });
 });
+
+describe("pickAggregateHealth", () => {
+  test("reports the least healthy replica", () => {
+    expect(pickAggregateHealth([{ Health: "healthy" }, { Health: "unhealthy" }])).toBe("unhealthy");
+    expect(pickAggregateHealth([{ Health: "healthy" }, { Health: "starting" }])).toBe("starting");
+    expect(pickAggregateHealth([{ Health: "healthy" }])).toBe("healthy");
+  });
+
+  test("returns null when no healthcheck is defined", () => {
+    expect(pickAggregateHealth([{ Health: "" }, {}])).toBeNull();
+  });
+});
+
+describe("isDockerStateEvent", () => {
+  test("accepts lifecycle and health events", () => {
+    expect(isDockerStateEvent('{"action":"die","service":"db"}')).toBe(true);
+    expect(isDockerStateEvent('{"action":"health_status: unhealthy","service":"db"}')).toBe(true);
+  });
+
+  test("ignores healthcheck exec events and malformed lines", () => {
+    expect(isDockerStateEvent('{"action":"exec_start: pg_isready","service":"db"}')).toBe(false);
+    expect(isDockerStateEvent("not json")).toBe(false);
+  });
+});
+
+describe("DockerManager", () => {
+  test("reports itself offline when compose cannot list containers", async () => {
+    const docker = new DockerManager("/nonexistent/stasium/compose.yml");
+    expect(docker.getOfflineReason()).toBeNull();
+
+    await docker.refresh();
+    expect(docker.getOfflineReason()).not.toBeNull();
+    expect(docker.getServices()).toEqual([]);
+  });
+});
@@ -1,7 +1,7 @@
 import { resolve } from "node:path";
-import { LogBuffer } from "./log-buffer";
-import { fileExists } from "./shared";
-import type { DockerService, DockerServiceState } from "./types";
+import { DEFAULT_LOG_CAPACITY, LogBuffer } from "./log-buffer";
+import { fileExists, getErrorMessage } from "./shared";
+import type { DockerHealth, DockerService, DockerServiceState } from "./types";
 
 const COMPOSE_FILES = ["compose.yml", "compose.yaml", "docker-compose.yml", "docker-compose.yaml"];
 
@@ -73,7 +73,12 @@ export const detectComposeFile = async (cwd: string): Promise<string | null> =>
 
 export type DockerUpdateCallback = () => void;
 
-const LOG_CAPACITY = 2000;
+export class DockerError extends Error {
+  constructor(message: string) {
+    super(message);
+    this.name = "DockerError";
+  }
+}
 
 const parseDockerState = (state: string): DockerServiceState => {
   const lower = state.toLowerCase();
@@ -120,10 +125,34 @@ const pickAggregateState = (entries: DockerPsEntry[]): DockerServiceState => {
   return "unknown";
 };
 
+// The least healthy replica wins, so one failing container flags the whole service.
+export const pickAggregateHealth = (entries: Array<{ Health?: string }>): DockerHealth | null => {
+  const health = entries.map((entry) => entry.Health?.toLowerCase() ?? "");
+  const priority: DockerHealth[] = ["unhealthy", "starting", "healthy"];
+  for (const value of priority) {
+    if (health.includes(value)) return value;
+  }
+  return null;
+};
+
+// Healthchecks emit exec_* events on every probe; only lifecycle changes warrant a refresh.
+export const isDockerStateEvent = (line: string): boolean => {
+  try {
+    const event = JSON.parse(line) as { action?: unknown };
+    return typeof event.action === "string" && !event.action.startsWith("exec_");
+  } catch {
+    return false;
+  }
+};
+
+// Bursts such as `up` on a whole project collapse into a single refresh.
+const EVENT_REFRESH_DELAY_MS = 250;
+
 interface DockerPsEntry {
   Name?: string;
   Service?: string;
   State?: string;
+  Health?: string;
   Status?: string;
   Ports?: string;
 }
@@ -137,11 +166,18 @@ export class DockerManager {
   private readonly updateCallbacks: Set<DockerUpdateCallback> = new Set();
   private pollTimer: ReturnType<typeof setInterval> | null = null;
   private refreshing = false;
+  private refreshQueued = false;
+  private offlineReason: string | null = null;
+  private eventProcess: Bun.Subprocess | null = null;
+  private eventRefreshTimer: ReturnType<typeof setTimeout> | null = null;
   private activeLogProcess: { proc: Bun.Subprocess; name: string } | null = null;
   private activeLogService: string | null = null;
+  private readonly withContainers: Set<string> = new Set();
+  private readonly logLines: number;
 
-  constructor(composePath: string) {
+  constructor(composePath: string, logLines = DEFAULT_LOG_CAPACITY) {
     this.composePath = composePath;
+    this.logLines = logLines;
     this.cwd = resolve(composePath, "..");
   }
 
@@ -155,6 +191,30 @@ export class DockerManager {
     return await proc.exited;
   }
 
+  // Like runCompose, but fails with the last line compose printed to stderr.
+  private async runComposeChecked(args: string[]): Promise<void> {
+    const proc = Bun.spawn({
+      cmd: ["docker", "compose", "-f", this.composePath, ...args],
+      cwd: this.cwd,
+      stdout: "pipe",
+      stderr: "pipe",
+    });
+    const stderr = await new Response(proc.stderr).text();
+    const exitCode = await proc.exited;
+    if (exitCode !== 0) {
+      const reason = splitLines(stderr).at(-1) ?? `exit code ${exitCode}`;
+      throw new DockerError(`docker compose ${args[0]} failed: ${reason}`);
+    }
+  }
+
+  private requireContainer(name: string): DockerService {
+    const service = this.services.find((entry) => entry.name === name);
+    if (!service || !this.withContainers.has(name)) {
+      throw new DockerError(`${name} has no container`);
+    }
+    return service;
+  }
+
   onUpdate(callback: DockerUpdateCallback): () => void {
     this.updateCallbacks.add(callback);
     return () => this.updateCallbacks.delete(callback);
@@ -195,7 +255,7 @@ export class DockerManager {
   getLogBuffer(name: string): LogBuffer {
     let buffer = this.logs.get(name);
     if (!buffer) {
-      buffer = new LogBuffer(LOG_CAPACITY);
+      buffer = new LogBuffer(this.logLines);
       this.logs.set(name, buffer);
     }
     return buffer;
@@ -241,8 +301,20 @@ export class DockerManager {
         stderr: "pipe",
       });
 
-      const output = await new Response(proc.stdout).text();
-      await proc.exited;
+      const [output, stderr] = await Promise.all([
+        new Response(proc.stdout).text(),
+        new Response(proc.stderr).text(),
+      ]);
+      const exitCode = await proc.exited;
+      // The last known services stay listed so the panel does not look empty while offline.
+      if (exitCode !== 0) {
+        this.setOffline(splitLines(stderr).at(-1) ?? `docker compose ps exited with ${exitCode}`);
+        return;
+      }
+      const reconnected = this.offlineReason !== null;
+      this.offlineReason = null;
+      // The events stream ends when the daemon goes away, so resume it once ps works again.
+      if (reconnected && this.pollTimer && !this.eventProcess) this.watchEvents();
 
       const entries = parsePsOutput(output);
       const entriesByService = new Map<string, DockerPsEntry[]>();
@@ -260,6 +332,8 @@ export class DockerManager {
       }
 
       const serviceNames = getStableDockerServiceNames(configServices, entryOrder);
+      this.withContainers.clear();
+      for (const name of entryOrder) this.withContainers.add(name);
 
       const previousName = this.getSelectedService()?.name ?? null;
 
@@ -269,6 +343,7 @@ export class DockerManager {
           return {
             name,
             state: "created",
+            health: null,
             status: "",
             ports: "",
           };
@@ -281,6 +356,7 @@ export class DockerManager {
         return {
           name,
           state,
+          health: state === "running" ? pickAggregateHealth(list) : null,
           status: representative?.Status ?? "",
           ports: representative?.Ports ?? "",
         };
@@ -302,18 +378,39 @@ export class DockerManager {
       }
 
       this.notify();
-    } catch {
-      // docker compose not available or failed
+    } catch (error) {
+      this.setOffline(getErrorMessage(error));
     } finally {
       this.refreshing = false;
+      if (this.refreshQueued) {
+        this.refreshQueued = false;
+        void this.refresh();
+      }
     }
   }
 
+  // Null while the last `docker compose ps` succeeded; otherwise why it failed.
+  getOfflineReason(): string | null {
+    return this.offlineReason;
+  }
+
+  private setOffline(reason: string): void {
+    this.offlineReason = reason;
+    this.notify();
+  }
+
   async start(name: string): Promise<void> {
     await this.runCompose(["up", "-d", name]);
     await this.refresh();
   }
 
+  // Brings up the whole compose project, creating containers that do not exist yet.
+  async startAll(): Promise<void> {
+    await this.runCompose(["up", "-d"]);
+    await this.refresh();
+    this.streamSelectedLogs();
+  }
+
   async stop(name: string): Promise<void> {
     await this.runCompose(["stop", name]);
     await this.refresh();
@@ -327,6 +424,32 @@ export class DockerManager {
     await this.refresh();
   }
 
+  async pause(name: string): Promise<void> {
+    this.requireContainer(name);
+    await this.runComposeChecked(["pause", name]);
+    await this.refresh();
+  }
+
+  async unpause(name: string): Promise<void> {
+    this.requireContainer(name);
+    await this.runComposeChecked(["unpause", name]);
+    await this.refresh();
+  }
+
+  // Only stopped containers can be removed; running ones must be stopped first.
+  async remove(name: string): Promise<void> {
+    const service = this.requireContainer(name);
+    if (
+      service.state === "running" ||
+      service.state === "paused" ||
+      service.state === "restarting"
+    ) {
+      throw new DockerError(`stop ${name} before removing its container`);
+    }
+    await this.runComposeChecked(["rm", "-f", name]);
+    await this.refresh();
+  }
+
   async startSelected(): Promise<void> {
     const svc = this.getSelectedService();
     if (!svc) return;
@@ -348,6 +471,24 @@ export class DockerManager {
     this.streamSelectedLogs();
   }
 
+  async pauseSelected(): Promise<void> {
+    const svc = this.getSelectedService();
+    if (!svc) return;
+    await this.pause(svc.name);
+  }
+
+  async unpauseSelected(): Promise<void> {
+    const svc = this.getSelectedService();
+    if (!svc) return;
+    await this.unpause(svc.name);
+  }
+
+  async removeSelected(): Promise<void> {
+    const svc = this.getSelectedService();
+    if (!svc) return;
+    await this.remove(svc.name);
+  }
+
   streamLogs(name: string): void {
     this.stopLogStream();
     const buffer = this.getLogBuffer(name);
@@ -389,9 +530,12 @@ export class DockerManager {
     }
   }
 
+  // Container events drive refreshes as they happen; the interval stays as a backstop for
+  // when `compose events` is unavailable or its stream ends.
   startPolling(intervalMs = 3000): void {
     this.stopPolling();
     this.refresh();
+    this.watchEvents();
     this.pollTimer = setInterval(() => this.refresh(), intervalMs);
   }
 
@@ -400,6 +544,68 @@ export class DockerManager {
       clearInterval(this.pollTimer);
       this.pollTimer = null;
     }
+    this.stopEventWatch();
+  }
+
+  private watchEvents(): void {
+    let proc: Bun.Subprocess<"ignore", "pipe", "ignore">;
+    try {
+      proc = Bun.spawn({
+        cmd: ["docker", "compose", "-f", this.composePath, "events", "--json"],
+        cwd: this.cwd,
+        stdin: "ignore",
+        stdout: "pipe",
+        stderr: "ignore",
+      });
+    } catch {
+      return;
+    }
+    this.eventProcess = proc as Bun.Subprocess;
+
+    const reader = proc.stdout.getReader();
+    const decoder = new TextDecoder();
+    let remainder = "";
+    const readLoop = async () => {
+      while (true) {
+        const result = await reader.read();
+        if (result.done) break;
+        remainder += decoder.decode(result.value);
+        const parts = remainder.split(/\r?\n/);
+        remainder = parts.pop() ?? "";
+        if (parts.some(isDockerStateEvent)) this.scheduleEventRefresh();
+      }
+    };
+    readLoop()
+      .catch(() => {})
+      .finally(() => {
+        if (this.eventProcess === proc) this.eventProcess = null;
+      });
+  }
+
+  private scheduleEventRefresh(): void {
+    if (this.eventRefreshTimer) return;
+    this.eventRefreshTimer = setTimeout(() => {
+      this.eventRefreshTimer = null;
+      if (this.refreshing) {
+        this.refreshQueued = true;
+        return;
+      }
+      void this.refresh();
+    }, EVENT_REFRESH_DELAY_MS);
+  }
+
+  private stopEventWatch(): void {
+    if (this.eventRefreshTimer) {
+      clearTimeout(this.eventRefreshTimer);
+      this.eventRefreshTimer = null;
+    }
+    if (!this.eventProcess) return;
+    try {
+      this.eventProcess.kill("SIGTERM");
+    } catch {
+      // already dead
+    }
+    this.eventProcess = null;
   }
 
   async destroy(): Promise<void> {
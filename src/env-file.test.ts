@@ -0,0 +1,67 @@
+import { describe, expect, test } from "bun:test";
+import { mkdtemp, rm } from "node:fs/promises";
+import { tmpdir } from "node:os";
+import { join } from "node:path";
+import { parseEnvFile, resolveServiceEnv } from "./env-file";
+
+describe("parseEnvFile", () => {
+  test("handles comments, export prefixes, and quoting", () => {
+    const env = parseEnvFile(
+      [
+        "# database settings",
+        "DB_HOST=localhost",
+        "export DB_PORT=5432",
+        "",
+        'DB_PASSWORD="p@ss # not a comment"',
+        "DB_NAME='literal $HOME \\n'",
+        'GREETING="hello\\nworld"',
+        "APP_ENV=local # trailing comment",
+        "EMPTY=",
+        "not a pair",
+      ].join("\n"),
+    );
+
+    expect(env).toEqual({
+      DB_HOST: "localhost",
+      DB_PORT: "5432",
+      DB_PASSWORD: "p@ss # not a comment",
+      DB_NAME: "literal $HOME \\n",
+      GREETING: "hello\nworld",
+      APP_ENV: "local",
+      EMPTY: "",
+    });
+  });
+});
+
+describe("resolveServiceEnv", () => {
+  test("inline env overrides env_file values and later files override earlier ones", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-env-file-"));
+    await Bun.write(join(dir, ".env"), "APP_ENV=production\nAPP_DEBUG=false\nAPP_KEY=base\n");
+    await Bun.write(join(dir, ".env.local"), "APP_DEBUG=true\n");
+
+    try {
+      const resolved = await resolveServiceEnv([".env", ".env.local"], { APP_ENV: "local" }, dir);
+      expect(resolved.warnings).toEqual([]);
+      expect(resolved.env).toEqual({
+        APP_ENV: "local",
+        APP_DEBUG: "true",
+        APP_KEY: "base",
+      });
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("warns about missing env files without failing", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-env-file-"));
+
+    try {
+      const resolved = await resolveServiceEnv([".env.missing"], { APP_ENV: "local" }, dir);
+      expect(resolved.env).toEqual({ APP_ENV: "local" });
+      expect(resolved.warnings).toHaveLength(1);
+      expect(resolved.warnings[0]).toContain(".env.missing");
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+});
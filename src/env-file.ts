@@ -0,0 +1,101 @@
+import { resolve } from "node:path";
+import { fileExists, getErrorMessage } from "./shared";
+
+export interface ResolvedServiceEnv {
+  env: Record<string, string> | undefined;
+  warnings: string[];
+}
+
+const ENV_KEY_PATTERN = /^[A-Za-z_][A-Za-z0-9_.-]*$/;
+
+const unescapeDoubleQuoted = (value: string): string =>
+  value.replace(/\\([\\nrt"$])/g, (_, ch: string) => {
+    switch (ch) {
+      case "n":
+        return "\n";
+      case "r":
+        return "\r";
+      case "t":
+        return "\t";
+      default:
+        return ch;
+    }
+  });
+
+const findClosingQuote = (value: string, quote: string): number => {
+  for (let i = 1; i < value.length; i += 1) {
+    const ch = value.charAt(i);
+    if (quote === '"' && ch === "\\") {
+      i += 1;
+      continue;
+    }
+    if (ch === quote) return i;
+  }
+  return -1;
+};
+
+const parseValue = (raw: string): string => {
+  const value = raw.trim();
+  const quote = value.charAt(0);
+
+  if (quote === '"' || quote === "'") {
+    const close = findClosingQuote(value, quote);
+    if (close !== -1) {
+      const inner = value.slice(1, close);
+      return quote === '"' ? unescapeDoubleQuoted(inner) : inner;
+    }
+  }
+
+  const commentAt = value.search(/\s#/);
+  return commentAt === -1 ? value : value.slice(0, commentAt).trimEnd();
+};
+
+export const parseEnvFile = (contents: string): Record<string, string> => {
+  const env: Record<string, string> = {};
+
+  for (const rawLine of contents.split(/\r?\n/)) {
+    let line = rawLine.trim();
+    if (line.length === 0 || line.startsWith("#")) continue;
+    if (line.startsWith("export ")) {
+      line = line.slice("export ".length).trimStart();
+    }
+
+    const separator = line.indexOf("=");
+    if (separator <= 0) continue;
+
+    const key = line.slice(0, separator).trim();
+    if (!ENV_KEY_PATTERN.test(key)) continue;
+
+    env[key] = parseValue(line.slice(separator + 1));
+  }
+
+  return env;
+};
+
+export const resolveServiceEnv = async (
+  envFiles: string[] | undefined,
+  inlineEnv: Record<string, string> | undefined,
+  cwd: string,
+): Promise<ResolvedServiceEnv> => {
+  const warnings: string[] = [];
+  if (!envFiles || envFiles.length === 0) {
+    return { env: inlineEnv, warnings };
+  }
+
+  const merged: Record<string, string> = {};
+  for (const envFile of envFiles) {
+    const path = resolve(cwd, envFile);
+    if (!(await fileExists(path))) {
+      warnings.push(`env_file not found: ${path}`);
+      continue;
+    }
+
+    try {
+      Object.assign(merged, parseEnvFile(await Bun.file(path).text()));
+    } catch (error) {
+      warnings.push(`env_file could not be read: ${path}: ${getErrorMessage(error)}`);
+    }
+  }
+
+  return { env: { ...merged, ...inlineEnv }, warnings };
+};
@@ -26,6 +26,36 @@ describe("FocusManager", () => {
     ]);
   });
 
+  test("shows log search mode shortcuts", () => {
+    const focus = new FocusManager(false);
+    focus.setMode("searching");
+
+    const shortcuts = focus.getShortcuts();
+    expect(shortcuts.map((shortcut) => shortcut.label)).toEqual(["search", "clear"]);
+  });
+
+  test("groups every binding into help sections", () => {
+    const focus = new FocusManager(false);
+    const sections = focus.getHelpSections();
+
+    expect(sections.map((section) => section.title)).not.toContain("Docker");
+    const global = sections.find((section) => section.title === "Global");
+    expect(global?.shortcuts.map((shortcut) => shortcut.label)).not.toContain("docker panel");
+    expect(global?.shortcuts.some((shortcut) => shortcut.key === "?")).toBe(true);
+
+    focus.setMode("help");
+    expect(focus.getShortcuts().map((shortcut) => shortcut.label)).toEqual(["close"]);
+  });
+
+  test("dispatches panel keys through the configured keymap", () => {
+    const focus = new FocusManager(false, { manifest: { stop: "z" } });
+
+    expect(focus.findPanelShortcut("z")?.label).toBe("stop");
+    expect(focus.findPanelShortcut("x")).toBeUndefined();
+    expect(focus.findPanelShortcut("S")?.label).toBe("start all");
+    expect(focus.getShortcuts().find((shortcut) => shortcut.label === "stop")?.key).toBe("z");
+  });
+
   test("toggles panel visibility and moves focus off hidden panel", () => {
     const focus = new FocusManager(true);
     focus.setActivePanel("docker");
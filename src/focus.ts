@@ -1,4 +1,4 @@
-import type { AppMode, PanelId, Shortcut } from "./types";
+import type { AppKeysConfig, AppMode, PanelId, Shortcut, ShortcutSection } from "./types";
 
 export type FocusUpdateCallback = () => void;
 
@@ -6,10 +6,18 @@ const MANIFEST_SHORTCUTS: Shortcut[] = [
   { key: "s", label: "start" },
   { key: "x", label: "stop" },
   { key: "r", label: "restart" },
+  { key: "h", label: "reload svc" },
+  { key: "k", label: "signal" },
+  { key: "S", label: "start all" },
+  { key: "X", label: "stop all" },
+  { key: "R", label: "restart all" },
   { key: "a", label: "add" },
   { key: "i", label: "discover" },
   { key: "d", label: "delete" },
+  { key: "D", label: "disable" },
+  { key: "E", label: "enable" },
   { key: "e", label: "edit" },
+  { key: "y", label: "copy" },
   { key: "up/down", label: "select" },
 ];
 
@@ -19,12 +27,24 @@ const LOGS_SHORTCUTS: Shortcut[] = [
   { key: "g", label: "top" },
   { key: "G", label: "bottom" },
   { key: "c", label: "clear" },
+  { key: "/", label: "search" },
+  { key: "n/N", label: "next match" },
+  { key: "o", label: "stream" },
+  { key: "m", label: "matches only" },
+  { key: "t", label: "time" },
+  { key: "w", label: "save logs" },
+  { key: "y", label: "copy" },
 ];
 
 const DOCKER_SHORTCUTS: Shortcut[] = [
   { key: "s", label: "start" },
   { key: "x", label: "stop" },
   { key: "r", label: "restart" },
+  { key: "S", label: "start all" },
+  { key: "p", label: "pause" },
+  { key: "u", label: "unpause" },
+  { key: "d", label: "remove" },
+  { key: "y", label: "copy" },
   { key: "up/down", label: "select" },
 ];
 
@@ -48,6 +68,23 @@ const DISCOVERING_SHORTCUTS: Shortcut[] = [
   { key: "esc", label: "cancel" },
 ];
 
+const SEARCHING_SHORTCUTS: Shortcut[] = [
+  { key: "enter", label: "search" },
+  { key: "esc", label: "clear" },
+];
+
+const SIGNALING_SHORTCUTS: Shortcut[] = [
+  { key: "enter", label: "send" },
+  { key: "esc", label: "cancel" },
+];
+
+const ENABLING_SHORTCUTS: Shortcut[] = [
+  { key: "enter", label: "enable" },
+  { key: "esc", label: "cancel" },
+];
+
+const HELP_SHORTCUTS: Shortcut[] = [{ key: "esc/?", label: "close" }];
+
 const GLOBAL_SHORTCUTS: Shortcut[] = [
   { key: "pgup/pgdn", label: "log page" },
   { key: "home/end", label: "log jump" },
@@ -56,6 +93,8 @@ const GLOBAL_SHORTCUTS: Shortcut[] = [
   { key: "3", label: "logs panel" },
   { key: "4", label: "all panels" },
   { key: "tab", label: "switch panel" },
+  { key: "ctrl+r", label: "reload" },
+  { key: "?", label: "help" },
   { key: "q", label: "quit" },
 ];
 
@@ -65,11 +104,62 @@ const PANEL_SHORTCUTS: Record<PanelId, Shortcut[]> = {
   docker: DOCKER_SHORTCUTS,
 };
 
+// Entries such as "up/down" or "n/N" cover several keys and keep their fixed bindings.
+const keyParts = (shortcut: Shortcut): string[] => shortcut.key.split("/");
+
+const isRebindable = (shortcut: Shortcut): boolean => keyParts(shortcut).length === 1;
+
+const RESERVED_KEYS = new Set([...GLOBAL_SHORTCUTS.flatMap(keyParts), "esc"]);
+
+const applyKeymap = (shortcuts: Shortcut[], overrides?: Record<string, string>): Shortcut[] =>
+  shortcuts.map((shortcut) => {
+    const key = overrides?.[shortcut.label];
+    return key !== undefined && isRebindable(shortcut) ? { ...shortcut, key } : shortcut;
+  });
+
+// Returns a message for the first unusable [app.keys] entry, or null when the keymap is valid.
+export const findKeymapError = (keys: AppKeysConfig): string | null => {
+  for (const [panel, overrides] of Object.entries(keys)) {
+    if (!Object.hasOwn(PANEL_SHORTCUTS, panel)) return `app.keys has unknown panel: ${panel}`;
+    const defaults = PANEL_SHORTCUTS[panel as PanelId];
+    if (!overrides) continue;
+
+    for (const [label, key] of Object.entries(overrides)) {
+      const shortcut = defaults.find((entry) => entry.label === label);
+      if (!shortcut || !isRebindable(shortcut)) {
+        return `app.keys.${panel} has no rebindable action: ${label}`;
+      }
+      if (typeof key !== "string" || [...key].length !== 1 || key.trim() === "") {
+        return `app.keys.${panel}.${label} must be a single printable character`;
+      }
+      if (RESERVED_KEYS.has(key)) {
+        return `app.keys.${panel}.${label}: "${key}" is reserved for a global shortcut`;
+      }
+    }
+
+    const owners = new Map<string, string>();
+    for (const shortcut of applyKeymap(defaults, overrides)) {
+      for (const key of keyParts(shortcut)) {
+        const owner = owners.get(key);
+        if (owner !== undefined) {
+          return `app.keys.${panel}: "${key}" is bound to both ${owner} and ${shortcut.label}`;
+        }
+        owners.set(key, shortcut.label);
+      }
+    }
+  }
+  return null;
+};
+
 const MODE_SHORTCUTS: Record<AppMode, Shortcut[] | null> = {
   normal: null,
   editing: EDITING_SHORTCUTS,
   adding: ADDING_SHORTCUTS,
   discovering: DISCOVERING_SHORTCUTS,
+  searching: SEARCHING_SHORTCUTS,
+  signaling: SIGNALING_SHORTCUTS,
+  enabling: ENABLING_SHORTCUTS,
+  help: HELP_SHORTCUTS,
 };
 
 export class FocusManager {
@@ -77,12 +167,18 @@ export class FocusManager {
   private readonly panels: PanelId[];
   private visiblePanels: PanelId[];
   private mode: AppMode = "normal";
+  private readonly panelShortcuts: Record<PanelId, Shortcut[]>;
   private readonly updateCallbacks: Set<FocusUpdateCallback> = new Set();
 
-  constructor(hasDocker: boolean) {
+  constructor(hasDocker: boolean, keys: AppKeysConfig = {}) {
     this.panels = hasDocker ? ["manifest", "docker", "logs"] : ["manifest", "logs"];
     this.visiblePanels = [...this.panels];
     this.activePanel = "manifest";
+    this.panelShortcuts = {
+      manifest: applyKeymap(MANIFEST_SHORTCUTS, keys.manifest),
+      logs: applyKeymap(LOGS_SHORTCUTS, keys.logs),
+      docker: applyKeymap(DOCKER_SHORTCUTS, keys.docker),
+    };
   }
 
   onUpdate(callback: FocusUpdateCallback): () => void {
@@ -161,17 +257,46 @@ export class FocusManager {
   getShortcuts(): Shortcut[] {
     const modeShortcuts = MODE_SHORTCUTS[this.mode];
     if (modeShortcuts) return modeShortcuts;
-    const panelShortcuts = PANEL_SHORTCUTS[this.activePanel] ?? [];
-    const globalShortcuts = this.panels.includes("docker")
-      ? GLOBAL_SHORTCUTS
-      : GLOBAL_SHORTCUTS.filter((shortcut) => shortcut.label !== "docker panel");
-    return [...panelShortcuts, ...globalShortcuts];
+    const panelShortcuts = this.panelShortcuts[this.activePanel] ?? [];
+    return [...panelShortcuts, ...this.getGlobalShortcuts()];
+  }
+
+  // Looks up the active panel's single-key shortcut, after any [app.keys] overrides.
+  findPanelShortcut(key: string): Shortcut | undefined {
+    return this.panelShortcuts[this.activePanel]?.find((shortcut) => shortcut.key === key);
+  }
+
+  // Every binding grouped by where it applies; the footer draws from the same lists.
+  getHelpSections(): ShortcutSection[] {
+    const sections: ShortcutSection[] = [
+      { title: "Manifest", shortcuts: this.panelShortcuts.manifest },
+    ];
+    if (this.panels.includes("docker")) {
+      sections.push({ title: "Docker", shortcuts: this.panelShortcuts.docker });
+    }
+    sections.push(
+      { title: "Logs", shortcuts: this.panelShortcuts.logs },
+      { title: "Global", shortcuts: this.getGlobalShortcuts() },
+      { title: "Edit", shortcuts: EDITING_SHORTCUTS },
+      { title: "Add", shortcuts: ADDING_SHORTCUTS },
+      { title: "Discover", shortcuts: DISCOVERING_SHORTCUTS },
+      { title: "Log search", shortcuts: SEARCHING_SHORTCUTS },
+      { title: "Signal", shortcuts: SIGNALING_SHORTCUTS },
+      { title: "Enable", shortcuts: ENABLING_SHORTCUTS },
+    );
+    return sections;
   }
 
   isPanelActive(panel: PanelId): boolean {
     return this.activePanel === panel;
   }
 
+  private getGlobalShortcuts(): Shortcut[] {
+    return this.panels.includes("docker")
+      ? GLOBAL_SHORTCUTS
+      : GLOBAL_SHORTCUTS.filter((shortcut) => shortcut.label !== "docker panel");
+  }
+
   private notify(): void {
     for (const callback of this.updateCallbacks) {
       callback();
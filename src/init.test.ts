@@ -0,0 +1,89 @@
+import { describe, expect, test } from "bun:test";
+import { mkdir, mkdtemp, rm } from "node:fs/promises";
+import { tmpdir } from "node:os";
+import { join } from "node:path";
+import type { DiscoveryStrategy } from "./discovery";
+import { formatStrategySummary, listStrategies } from "./init";
+
+const makeStrategy = (defaultSelected: boolean): DiscoveryStrategy => ({
+  id: "node-dev",
+  label: "Node dev",
+  priority: 100,
+  default_selected: defaultSelected,
+  when: {
+    all_files: ["package.json"],
+    any_files: [],
+    all_json_paths: [],
+    any_json_paths: [],
+    all_toml_paths: [],
+    any_toml_paths: [],
+    all_regex: [],
+    any_regex: [],
+  },
+  capture: [],
+  service: {
+    name: "frontend",
+    command: ["bun", "run", "dev"],
+  },
+});
+
+const writeOverride = async (dir: string, toml: string): Promise<void> => {
+  await mkdir(join(dir, ".stasium"), { recursive: true });
+  await Bun.write(join(dir, ".stasium", "discovery.toml"), toml);
+};
+
+describe("init strategy listing", () => {
+  test("marks strategies that are not selected by default", () => {
+    expect(formatStrategySummary(makeStrategy(true))).toBe("node-dev: Node dev");
+    expect(formatStrategySummary(makeStrategy(false))).toBe("node-dev: Node dev (opt-in)");
+  });
+
+  test("merges project overrides into the built-in catalog", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-init-"));
+
+    try {
+      await writeOverride(
+        dir,
+        `
+version = 1
+
+[[strategy]]
+id = "make-dev"
+label = "Project make target"
+default_selected = false
+
+[strategy.when]
+all_files = ["Makefile"]
+
+[strategy.service]
+name = "dev"
+command = ["make", "serve"]
+        `,
+      );
+
+      const loaded = await listStrategies(dir);
+      expect(loaded.warnings).toEqual([]);
+      const overridden = loaded.strategies.find((strategy) => strategy.id === "make-dev");
+      expect(overridden && formatStrategySummary(overridden)).toBe(
+        "make-dev: Project make target (opt-in)",
+      );
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("reports invalid overrides as warnings and keeps the built-in catalog", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-init-"));
+
+    try {
+      await writeOverride(dir, "version = 1\n\n[[strategy]]\nid = 42\n");
+
+      const loaded = await listStrategies(dir);
+      expect(loaded.warnings).toHaveLength(1);
+      expect(loaded.warnings[0]).toStartWith("Ignoring .stasium/discovery.toml:");
+      expect(loaded.strategies.some((strategy) => strategy.id === "make-dev")).toBe(true);
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+});
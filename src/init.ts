@@ -5,7 +5,11 @@ import {
   finalizeSelection,
   loadDiscoveryStrategies,
 } from "./discovery";
-import type { DetectResult as DiscoveryDetectResult } from "./discovery";
+import type {
+  DetectResult as DiscoveryDetectResult,
+  DiscoveryStrategy,
+  LoadedStrategies,
+} from "./discovery";
 import { saveManifest } from "./manifest";
 import { formatCommandSpec } from "./shared";
 import type { AppConfig, ServiceConfig } from "./types";
@@ -24,6 +28,9 @@ export const detectServices = async (cwd: string): Promise<DetectResult> => {
   };
 };
 
+export const listStrategies = (cwd: string): Promise<LoadedStrategies> =>
+  loadDiscoveryStrategies(cwd);
+
 export const getDefaultServices = (
   detected: DetectResult,
 ): { services: ServiceConfig[]; warnings: string[] } => {
@@ -41,3 +48,6 @@ export const writeManifest = async (
 
 export const formatServiceSummary = (service: ServiceConfig): string =>
   `${service.name}: ${formatCommandSpec(service.command)}`;
+
+export const formatStrategySummary = (strategy: DiscoveryStrategy): string =>
+  `${strategy.id}: ${strategy.label}${strategy.default_selected ? "" : " (opt-in)"}`;
@@ -0,0 +1,44 @@
+import { describe, expect, test } from "bun:test";
+import { createInterpolationContext, interpolateService, interpolateString } from "./interpolate";
+
+describe("interpolation", () => {
+  test("resolves env, root, project, and custom vars", () => {
+    const context = createInterpolationContext(
+      "/work/shop",
+      { port: "8080", home: "${env:HOME}/shop" },
+      new Set(),
+      { HOME: "/home/dev" },
+    );
+
+    expect(interpolateString("${root}/${project}:${port}", context)).toBe("/work/shop/shop:8080");
+    expect(interpolateString("${home}", context)).toBe("/home/dev/shop");
+  });
+
+  test("allows the root itself to reference the environment", () => {
+    const context = createInterpolationContext("${env:WORKSPACE}/api", {}, new Set(), {
+      WORKSPACE: "/srv",
+    });
+
+    expect(context.root).toBe("/srv/api");
+  });
+
+  test("keeps unknown variables literal and reports them", () => {
+    const context = createInterpolationContext("/work", { port: "3000" }, new Set(), {});
+    const { service, unknown } = interpolateService(
+      {
+        name: "api",
+        command: ["bun", "run", "serve", "--port", "${port}", "${missing}"],
+        working_dir: "${root}/api",
+        env: { TOKEN: "${env:API_TOKEN}" },
+        env_file: ["${root}/.env"],
+      },
+      context,
+    );
+
+    expect(service.command).toEqual(["bun", "run", "serve", "--port", "3000", "${missing}"]);
+    expect(service.working_dir).toBe("/work/api");
+    expect(service.env).toEqual({ TOKEN: "${env:API_TOKEN}" });
+    expect(service.env_file).toEqual(["/work/.env"]);
+    expect(unknown.sort()).toEqual(["env:API_TOKEN", "missing"]);
+  });
+});
@@ -0,0 +1,91 @@
+import { basename } from "node:path";
+import type { ServiceConfig } from "./types";
+
+export interface InterpolationContext {
+  root: string;
+  vars: Record<string, string>;
+  env?: NodeJS.ProcessEnv;
+}
+
+const VARIABLE_PATTERN = /\$\{([^{}]+)\}/g;
+const ENV_PREFIX = "env:";
+
+const lookupVariable = (
+  name: string,
+  context: InterpolationContext,
+  vars: Record<string, string>,
+): string | undefined => {
+  if (name.startsWith(ENV_PREFIX)) {
+    return (context.env ?? process.env)[name.slice(ENV_PREFIX.length)];
+  }
+  if (name === "root") return context.root;
+  if (name === "project") return basename(context.root);
+  return Object.hasOwn(vars, name) ? vars[name] : undefined;
+};
+
+const replaceVariables = (
+  value: string,
+  context: InterpolationContext,
+  vars: Record<string, string>,
+  unknown: Set<string>,
+): string =>
+  value.replace(VARIABLE_PATTERN, (match, rawName: string) => {
+    const name = rawName.trim();
+    const resolved = lookupVariable(name, context, vars);
+    if (resolved === undefined) {
+      unknown.add(name);
+      return match;
+    }
+    return resolved;
+  });
+
+export const interpolateString = (
+  value: string,
+  context: InterpolationContext,
+  unknown: Set<string> = new Set(),
+): string => replaceVariables(value, context, context.vars, unknown);
+
+// Custom vars may reference ${env:...}, ${root} and ${project}, but not each other.
+export const createInterpolationContext = (
+  root: string,
+  vars: Record<string, string> = {},
+  unknown: Set<string> = new Set(),
+  env?: NodeJS.ProcessEnv,
+): InterpolationContext => {
+  const base: InterpolationContext = { root, vars: {}, env };
+  const resolvedRoot = replaceVariables(root, base, {}, unknown);
+  const context: InterpolationContext = { root: resolvedRoot, vars: {}, env };
+
+  for (const [key, value] of Object.entries(vars)) {
+    context.vars[key] = replaceVariables(value, context, {}, unknown);
+  }
+
+  return context;
+};
+
+export const interpolateService = (
+  service: ServiceConfig,
+  context: InterpolationContext,
+): { service: ServiceConfig; unknown: string[] } => {
+  const unknown = new Set<string>();
+  const apply = (value: string): string => interpolateString(value, context, unknown);
+
+  const command = Array.isArray(service.command)
+    ? service.command.map(apply)
+    : apply(service.command);
+
+  const env = service.env
+    ? Object.fromEntries(Object.entries(service.env).map(([key, value]) => [key, apply(value)]))
+    : undefined;
+
+  return {
+    service: {
+      ...service,
+      command,
+      working_dir: service.working_dir === undefined ? undefined : apply(service.working_dir),
+      env,
+      env_file: service.env_file?.map(apply),
+    },
+    unknown: [...unknown],
+  };
+};
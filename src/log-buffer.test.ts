@@ -0,0 +1,25 @@
+import { describe, expect, test } from "bun:test";
+import { LogBuffer } from "./log-buffer";
+import type { LogEntry } from "./types";
+
+const entry = (line: string): LogEntry => ({
+  timestamp: "2026-01-02T03:04:05.000Z",
+  line,
+  stream: "stdout",
+});
+
+describe("LogBuffer", () => {
+  test("counts lines evicted past capacity", () => {
+    const buffer = new LogBuffer(2);
+    buffer.add(entry("one"));
+    buffer.add(entry("two"));
+    buffer.add(entry("three"));
+
+    expect(buffer.all().map((item) => item.line)).toEqual(["two", "three"]);
+    expect(buffer.getDroppedCount()).toBe(1);
+    expect(buffer.getFullText().split("\n")[0]).toBe("[1 earlier lines dropped]");
+
+    buffer.clear();
+    expect(buffer.getDroppedCount()).toBe(0);
+  });
+});
@@ -1,5 +1,7 @@
 import type { LogEntry } from "./types";
 
+export const DEFAULT_LOG_CAPACITY = 5000;
+
 const formatLogLine = (entry: LogEntry): string => {
   const streamLabel = entry.stream === "stderr" ? "ERR" : "OUT";
   return `${entry.timestamp} [${streamLabel}] ${entry.line}`;
@@ -9,6 +11,7 @@ export class LogBuffer {
   private readonly capacity: number;
   private entries: LogEntry[] = [];
   private version = 0;
+  private dropped = 0;
 
   constructor(capacity: number) {
     this.capacity = capacity;
@@ -17,7 +20,9 @@ export class LogBuffer {
   add(entry: LogEntry): void {
     this.entries.push(entry);
     if (this.entries.length > this.capacity) {
-      this.entries.splice(0, this.entries.length - this.capacity);
+      const overflow = this.entries.length - this.capacity;
+      this.entries.splice(0, overflow);
+      this.dropped += overflow;
     }
     this.version += 1;
   }
@@ -28,6 +33,7 @@ export class LogBuffer {
 
   clear(): void {
     this.entries = [];
+    this.dropped = 0;
     this.version += 1;
   }
 
@@ -35,8 +41,15 @@ export class LogBuffer {
     return this.version;
   }
 
+  // Lines evicted to stay within capacity since the last clear.
+  getDroppedCount(): number {
+    return this.dropped;
+  }
+
   getFullText(): string {
-    return this.entries.map(formatLogLine).join("\n");
+    const lines = this.entries.map(formatLogLine);
+    if (this.dropped > 0) lines.unshift(`[${this.dropped} earlier lines dropped]`);
+    return lines.join("\n");
   }
 
   size(): number {
@@ -0,0 +1,44 @@
+import { describe, expect, test } from "bun:test";
+import { mkdtemp, rm } from "node:fs/promises";
+import { tmpdir } from "node:os";
+import { join } from "node:path";
+import { watchManifest } from "./manifest-watcher";
+
+const delay = (ms: number): Promise<void> => new Promise((resolve) => setTimeout(resolve, ms));
+
+describe("manifest watcher", () => {
+  test("debounces edits and ignores contents it was told about", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-watch-"));
+    const manifestPath = join(dir, "stasium.toml");
+    await Bun.write(manifestPath, "# v1\n");
+
+    let changes = 0;
+    const watcher = watchManifest(
+      manifestPath,
+      "# v1\n",
+      async () => {
+        changes += 1;
+      },
+      50,
+    );
+
+    try {
+      await Bun.write(manifestPath, "# v2\n");
+      await Bun.write(manifestPath, "# v3\n");
+      await delay(300);
+      expect(changes).toBe(1);
+
+      watcher.markWritten("# v4\n");
+      await Bun.write(manifestPath, "# v4\n");
+      await delay(300);
+      expect(changes).toBe(1);
+
+      await Bun.write(join(dir, "other.txt"), "noise");
+      await delay(300);
+      expect(changes).toBe(1);
+    } finally {
+      watcher.close();
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+});
@@ -0,0 +1,58 @@
+import { watch } from "node:fs";
+import { readFile } from "node:fs/promises";
+import { basename, dirname } from "node:path";
+
+export const MANIFEST_WATCH_DEBOUNCE_MS = 250;
+
+export interface ManifestWatcher {
+  markWritten: (contents: string) => void;
+  close: () => void;
+}
+
+// Watches the directory rather than the file so editors that save by renaming a temp file over
+// the manifest are still noticed. A change only fires once the contents differ from what was
+// last seen, which also filters out stasium's own saves once they are marked as written.
+export const watchManifest = (
+  path: string,
+  contents: string | null,
+  onChange: () => Promise<void>,
+  debounceMs = MANIFEST_WATCH_DEBOUNCE_MS,
+): ManifestWatcher => {
+  const name = basename(path);
+  let lastContents = contents;
+  let timer: ReturnType<typeof setTimeout> | null = null;
+  let closed = false;
+
+  const check = async () => {
+    timer = null;
+    let next: string;
+    try {
+      next = await readFile(path, "utf8");
+    } catch {
+      return;
+    }
+    if (closed || next === lastContents) return;
+    lastContents = next;
+    await onChange();
+  };
+
+  const watcher = watch(dirname(path), (_event, filename) => {
+    if (closed || (filename !== null && filename !== name)) return;
+    if (timer) clearTimeout(timer);
+    timer = setTimeout(() => {
+      void check();
+    }, debounceMs);
+  });
+
+  return {
+    markWritten: (written) => {
+      lastContents = written;
+    },
+    close: () => {
+      closed = true;
+      if (timer) clearTimeout(timer);
+      timer = null;
+      watcher.close();
+    },
+  };
+};
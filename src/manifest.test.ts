@@ -2,7 +2,14 @@ import { describe, expect, test } from "bun:test";
 import { mkdtemp, rm } from "node:fs/promises";
 import { tmpdir } from "node:os";
 import { join } from "node:path";
-import { ManifestError, loadManifest, renderManifest } from "./manifest";
+import {
+  ManifestError,
+  getManifestVars,
+  loadManifest,
+  renderManifest,
+  saveManifest,
+  setServiceEnabled,
+} from "./manifest";
 import type { AppConfig, ServiceConfig } from "./types";
 
 const writeTempManifest = async (
@@ -33,6 +40,135 @@ describe("manifest rendering", () => {
     }
   });
 
+  test("round-trips env_file paths", async () => {
+    const { manifestPath, dir } = await writeTempManifest([
+      {
+        name: "api",
+        command: ["bun", "run", "dev"],
+        env_file: [".env", ".env.local"],
+      },
+    ]);
+
+    try {
+      const manifest = await loadManifest(manifestPath);
+      expect(manifest.services[0]?.env_file).toEqual([".env", ".env.local"]);
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("round-trips crash loop limits", async () => {
+    const { manifestPath, dir } = await writeTempManifest([
+      {
+        name: "api",
+        command: ["bun", "run", "dev"],
+        restart_policy: "always",
+        restart_limit: 3,
+        restart_window: "2m",
+      },
+    ]);
+
+    try {
+      const manifest = await loadManifest(manifestPath);
+      expect(manifest.services[0]?.restart_limit).toBe(3);
+      expect(manifest.services[0]?.restart_window).toBe("2m");
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("loads and rejects start_delay durations", async () => {
+    const valid = await writeTempManifest([
+      { name: "db", command: ["postgres"], start_delay: "2s" },
+    ]);
+    const invalid = await writeTempManifest([
+      { name: "db", command: ["postgres"], start_delay: "later" },
+    ]);
+
+    try {
+      const manifest = await loadManifest(valid.manifestPath);
+      expect(manifest.services[0]?.start_delay).toBe("2s");
+      await expect(loadManifest(invalid.manifestPath)).rejects.toThrow(ManifestError);
+    } finally {
+      await rm(valid.dir, { recursive: true, force: true });
+      await rm(invalid.dir, { recursive: true, force: true });
+    }
+  });
+
+  test("loads and rejects resource alert budgets", async () => {
+    const valid = await writeTempManifest([
+      { name: "api", command: ["bun", "run", "dev"], cpu_alert: 80, mem_alert: 512 },
+    ]);
+    const invalid = await writeTempManifest([
+      { name: "api", command: ["bun", "run", "dev"], mem_alert: 0 },
+    ]);
+
+    try {
+      const manifest = await loadManifest(valid.manifestPath);
+      expect(manifest.services[0]?.cpu_alert).toBe(80);
+      expect(manifest.services[0]?.mem_alert).toBe(512);
+      await expect(loadManifest(invalid.manifestPath)).rejects.toThrow(ManifestError);
+    } finally {
+      await rm(valid.dir, { recursive: true, force: true });
+      await rm(invalid.dir, { recursive: true, force: true });
+    }
+  });
+
+  test("accepts timeouts only on oneshot services", async () => {
+    const valid = await writeTempManifest([
+      { name: "migrate", command: ["bun", "run", "migrate"], oneshot: true, timeout: "30s" },
+    ]);
+    const invalid = await writeTempManifest([
+      { name: "api", command: ["bun", "run", "dev"], timeout: "30s" },
+    ]);
+
+    try {
+      const manifest = await loadManifest(valid.manifestPath);
+      expect(manifest.services[0]?.timeout).toBe("30s");
+      await expect(loadManifest(invalid.manifestPath)).rejects.toThrow(ManifestError);
+    } finally {
+      await rm(valid.dir, { recursive: true, force: true });
+      await rm(invalid.dir, { recursive: true, force: true });
+    }
+  });
+
+  test("rejects malformed restart windows", async () => {
+    const { manifestPath, dir } = await writeTempManifest([
+      { name: "api", command: ["bun", "run", "dev"], restart_window: "soon" },
+    ]);
+
+    try {
+      await expect(loadManifest(manifestPath)).rejects.toThrow(ManifestError);
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("loads vars and warns about unknown variables", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-manifest-"));
+    const manifestPath = join(dir, "stasium.toml");
+    await Bun.write(
+      manifestPath,
+      [
+        "[vars]",
+        'port = "8080"',
+        "",
+        "[[service]]",
+        'name = "api"',
+        'command = ["bun", "run", "serve", "--port", "${port}", "${nope}"]',
+      ].join("\n"),
+    );
+
+    try {
+      const manifest = await loadManifest(manifestPath);
+      expect(manifest.vars).toEqual({ port: "8080" });
+      expect(manifest.services[0]?.command).toContain("${port}");
+      expect(manifest.warnings).toEqual(["service api references unknown variables: nope"]);
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
   test("rejects dependency cycles", async () => {
     const { manifestPath, dir } = await writeTempManifest([
       {
@@ -78,6 +214,166 @@ describe("manifest rendering", () => {
     }
   });
 
+  test("loads app confirm_stop config", async () => {
+    const { manifestPath, dir } = await writeTempManifest([], {
+      confirm_stop: false,
+      docker: { enabled: true },
+    });
+
+    try {
+      const manifest = await loadManifest(manifestPath);
+      expect(manifest.app?.confirm_stop).toBe(false);
+      expect(manifest.app?.docker?.enabled).toBe(true);
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("loads app log_lines config", async () => {
+    const { manifestPath, dir } = await writeTempManifest([], { log_lines: 20000 });
+
+    try {
+      const manifest = await loadManifest(manifestPath);
+      expect(manifest.app?.log_lines).toBe(20000);
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("rejects non-positive log_lines values", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-manifest-"));
+    const manifestPath = join(dir, "stasium.toml");
+    await Bun.write(manifestPath, ["[app]", "log_lines = 0"].join("\n"));
+
+    try {
+      await expect(loadManifest(manifestPath)).rejects.toThrow(ManifestError);
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("loads app log_timestamps config", async () => {
+    const { manifestPath, dir } = await writeTempManifest([], { log_timestamps: "relative" });
+
+    try {
+      const manifest = await loadManifest(manifestPath);
+      expect(manifest.app?.log_timestamps).toBe("relative");
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("rejects unknown log_timestamps formats", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-manifest-"));
+    const manifestPath = join(dir, "stasium.toml");
+    await Bun.write(manifestPath, ["[app]", 'log_timestamps = "epoch"'].join("\n"));
+
+    try {
+      await expect(loadManifest(manifestPath)).rejects.toThrow(ManifestError);
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("loads app poll_interval config", async () => {
+    const { manifestPath, dir } = await writeTempManifest([], { poll_interval: "5s" });
+
+    try {
+      const manifest = await loadManifest(manifestPath);
+      expect(manifest.app?.poll_interval).toBe("5s");
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("loads the app theme and rejects unknown ones", async () => {
+    const { manifestPath, dir } = await writeTempManifest([], { theme: "high-contrast" });
+
+    try {
+      const manifest = await loadManifest(manifestPath);
+      expect(manifest.app?.theme).toBe("high-contrast");
+
+      await Bun.write(manifestPath, ["[app]", 'theme = "solarized"'].join("\n"));
+      await expect(loadManifest(manifestPath)).rejects.toThrow(ManifestError);
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("rejects poll_interval values below 100ms", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-manifest-"));
+    const manifestPath = join(dir, "stasium.toml");
+    await Bun.write(manifestPath, ["[app]", 'poll_interval = "50ms"'].join("\n"));
+
+    try {
+      await expect(loadManifest(manifestPath)).rejects.toThrow(ManifestError);
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("round-trips app key bindings and rejects conflicting ones", async () => {
+    const { manifestPath, dir } = await writeTempManifest([], {
+      keys: { manifest: { stop: "z", "stop all": "Z" } },
+    });
+
+    try {
+      const manifest = await loadManifest(manifestPath);
+      expect(manifest.app?.keys?.manifest).toEqual({ stop: "z", "stop all": "Z" });
+
+      await Bun.write(manifestPath, ["[app.keys.manifest]", 'stop = "s"'].join("\n"));
+      await expect(loadManifest(manifestPath)).rejects.toThrow('"s" is bound to both start');
+
+      await Bun.write(manifestPath, ["[app.keys.logs]", 'follow = "q"'].join("\n"));
+      await expect(loadManifest(manifestPath)).rejects.toThrow("reserved");
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("rejects unsupported reload signals", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-manifest-"));
+    const manifestPath = join(dir, "stasium.toml");
+    await Bun.write(
+      manifestPath,
+      ["[[service]]", 'name = "api"', 'command = "bun run dev"', 'reload_signal = "SIGKILL"'].join(
+        "\n",
+      ),
+    );
+
+    try {
+      await expect(loadManifest(manifestPath)).rejects.toThrow(ManifestError);
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("rejects non-boolean confirm_stop values", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-manifest-"));
+    const manifestPath = join(dir, "stasium.toml");
+    await Bun.write(manifestPath, ["[app]", 'confirm_stop = "no"'].join("\n"));
+
+    try {
+      await expect(loadManifest(manifestPath)).rejects.toThrow(ManifestError);
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("asks for an upgrade when the manifest version is newer", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-manifest-"));
+    const manifestPath = join(dir, "stasium.toml");
+    await Bun.write(manifestPath, ["version = 99", renderManifest([])].join("\n"));
+
+    try {
+      await expect(loadManifest(manifestPath)).rejects.toThrow(
+        "unsupported manifest version 99, please upgrade stasium",
+      );
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
   test("rejects non-boolean docker enabled values", async () => {
     const dir = await mkdtemp(join(tmpdir(), "stasium-manifest-"));
     const manifestPath = join(dir, "stasium.toml");
@@ -90,3 +386,166 @@ describe("manifest rendering", () => {
     }
   });
 });
+
+describe("manifest includes", () => {
+  test("merges included services with the including file winning", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-manifest-"));
+    const manifestPath = join(dir, "stasium.toml");
+    await Bun.write(
+      join(dir, "infra", "infra.toml"),
+      renderManifest([
+        { name: "db", command: ["postgres"] },
+        { name: "api", command: ["bun", "run", "old"] },
+      ]),
+    );
+    await Bun.write(
+      manifestPath,
+      renderManifest([{ name: "api", command: ["bun", "run", "dev"] }], undefined, undefined, {
+        include: ["infra/infra.toml"],
+      }),
+    );
+
+    try {
+      const manifest = await loadManifest(manifestPath);
+      expect(manifest.services.map((service) => service.name)).toEqual(["db", "api"]);
+      expect(manifest.services[1]?.command).toEqual(["bun", "run", "dev"]);
+      expect(manifest.includes?.services.map((service) => service.name)).toEqual(["db"]);
+      expect(manifest.includes?.names).toEqual(["db", "api"]);
+
+      await saveManifest(manifestPath, manifest.services, manifest.app, manifest.vars, {
+        includes: manifest.includes,
+      });
+      const saved = await Bun.file(manifestPath).text();
+      expect(saved).toContain('include = ["infra/infra.toml"]');
+      expect(saved).not.toContain('name = "db"');
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("rejects include cycles", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-manifest-"));
+    const manifestPath = join(dir, "stasium.toml");
+    await Bun.write(manifestPath, 'include = ["other.toml"]\n');
+    await Bun.write(join(dir, "other.toml"), 'include = ["stasium.toml"]\n');
+
+    try {
+      await expect(loadManifest(manifestPath)).rejects.toThrow("include cycle");
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+});
+
+describe("manifest profiles", () => {
+  const writeProfileManifest = async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-manifest-"));
+    const manifestPath = join(dir, "stasium.toml");
+    await Bun.write(
+      manifestPath,
+      renderManifest(
+        [
+          { name: "api", command: ["bun", "run", "${mode}"] },
+          { name: "fixtures", command: ["bun", "run", "seed"], profiles: ["test"] },
+        ],
+        undefined,
+        { mode: "dev" },
+        { profiles: { test: { mode: "test" } } },
+      ),
+    );
+    return { manifestPath, dir };
+  };
+
+  test("runs tagged services and overlays vars only for the selected profile", async () => {
+    const { manifestPath, dir } = await writeProfileManifest();
+
+    try {
+      const base = await loadManifest(manifestPath);
+      expect(base.services.map((service) => service.name)).toEqual(["api"]);
+      expect(getManifestVars(base)).toEqual({ mode: "dev" });
+
+      const test = await loadManifest(manifestPath, "test");
+      expect(test.services.map((service) => service.name)).toEqual(["api", "fixtures"]);
+      expect(getManifestVars(test)).toEqual({ mode: "test" });
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("keeps services outside the profile when saving", async () => {
+    const { manifestPath, dir } = await writeProfileManifest();
+
+    try {
+      const manifest = await loadManifest(manifestPath);
+      await saveManifest(manifestPath, manifest.services, manifest.app, manifest.vars, manifest);
+      const saved = await loadManifest(manifestPath, "test");
+      expect(saved.services.map((service) => service.name)).toEqual(["api", "fixtures"]);
+      expect(saved.profiles).toEqual({ test: { mode: "test" } });
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("rejects unknown profiles", async () => {
+    const { manifestPath, dir } = await writeProfileManifest();
+
+    try {
+      await expect(loadManifest(manifestPath, "staging")).rejects.toThrow("unknown profile");
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+});
+
+describe("disabled services", () => {
+  test("skips disabled services and flips them back on", async () => {
+    const { manifestPath, dir } = await writeTempManifest([
+      { name: "api", command: ["bun", "run", "dev"] },
+      { name: "worker", command: ["bun", "run", "worker"], enabled: false },
+    ]);
+
+    try {
+      const manifest = await loadManifest(manifestPath);
+      expect(manifest.services.map((service) => service.name)).toEqual(["api"]);
+
+      await setServiceEnabled(manifestPath, "worker", true);
+      const enabled = await loadManifest(manifestPath);
+      expect(enabled.services.map((service) => service.name)).toEqual(["api", "worker"]);
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("keeps a disabled service where it was in the file", async () => {
+    const { manifestPath, dir } = await writeTempManifest([
+      { name: "db", command: ["postgres"] },
+      { name: "worker", command: ["bun", "run", "worker"] },
+      { name: "api", command: ["bun", "run", "dev"] },
+    ]);
+
+    try {
+      await setServiceEnabled(manifestPath, "worker", false);
+      const contents = await Bun.file(manifestPath).text();
+      const names = [...contents.matchAll(/^name = "(\w+)"$/gm)].map((match) => match[1]);
+      expect(names).toEqual(["db", "worker", "api"]);
+      expect((await loadManifest(manifestPath)).serviceOrder).toEqual(["db", "worker", "api"]);
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("refuses to disable a dependency of an enabled service", async () => {
+    const { manifestPath, dir } = await writeTempManifest([
+      { name: "db", command: ["postgres"] },
+      { name: "api", command: ["bun", "run", "dev"], depends_on: ["db"] },
+    ]);
+
+    try {
+      await expect(setServiceEnabled(manifestPath, "db", false)).rejects.toThrow(
+        "cannot disable db: api depend on it",
+      );
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+});
@@ -1,18 +1,40 @@
-import { resolve } from "node:path";
+import { dirname, resolve } from "node:path";
+import { findKeymapError } from "./focus";
+import { createInterpolationContext, interpolateService } from "./interpolate";
+import { parseListenAddress } from "./metrics";
 import { ServiceGraphError, validateServiceGraph } from "./service-graph";
-import { getErrorMessage } from "./shared";
-import type { AppConfig, AppDockerConfig, Manifest, ServiceConfig } from "./types";
+import { getErrorMessage, parseDuration } from "./shared";
+import type {
+  AppConfig,
+  AppDockerConfig,
+  AppKeysConfig,
+  AppTheme,
+  LogTimestampFormat,
+  Manifest,
+  ManifestIncludes,
+  PanelId,
+  ServiceConfig,
+} from "./types";
 
 type RawManifest = {
+  version?: unknown;
+  include?: unknown;
   app?: {
     docker?: {
       enabled?: boolean;
     };
+    confirm_stop?: boolean;
+    log_lines?: number;
+    log_timestamps?: string;
+    metrics?: string;
   };
+  vars?: Record<string, unknown>;
+  profiles?: unknown;
   service?: ServiceConfig[];
 };
 
 const DEFAULT_MANIFEST = "stasium.toml";
+export const MANIFEST_VERSION = 1;
 
 export class ManifestError extends Error {
   constructor(message: string) {
@@ -26,20 +48,48 @@ const validServiceKeys = new Set([
   "command",
   "working_dir",
   "env",
+  "env_file",
   "restart_policy",
+  "restart_limit",
+  "restart_window",
+  "start_delay",
+  "cpu_alert",
+  "mem_alert",
+  "reload_signal",
   "depends_on",
+  "profiles",
+  "enabled",
+  "oneshot",
+  "timeout",
 ]);
 
 const validRestartPolicies = new Set(["never", "on-failure", "always"]);
-const validAppKeys = new Set(["docker"]);
+const validReloadSignals = new Set(["SIGHUP", "SIGUSR1", "SIGUSR2"]);
+const validAppKeys = new Set([
+  "docker",
+  "confirm_stop",
+  "log_lines",
+  "resource_history",
+  "log_timestamps",
+  "metrics",
+  "poll_interval",
+  "keys",
+  "theme",
+]);
+const validLogTimestampFormats = new Set(["clock", "precise", "relative"]);
+const validThemes = new Set(["auto", "dark", "light", "high-contrast", "mono"]);
 const validDockerKeys = new Set(["enabled"]);
-
-const normalizeEnv = (env: unknown): Record<string, string> | undefined => {
-  if (env === undefined) return undefined;
-  if (env === null || typeof env !== "object" || Array.isArray(env)) {
-    throw new ManifestError("service.env must be a table of string values");
+const MIN_POLL_INTERVAL_MS = 100;
+
+const normalizeStringTable = (
+  table: unknown,
+  label: string,
+): Record<string, string> | undefined => {
+  if (table === undefined) return undefined;
+  if (table === null || typeof table !== "object" || Array.isArray(table)) {
+    throw new ManifestError(`${label} must be a table of string values`);
   }
-  const entries = Object.entries(env as Record<string, unknown>);
+  const entries = Object.entries(table as Record<string, unknown>);
   const normalized: Record<string, string> = {};
   for (const [key, value] of entries) {
     if (value === undefined) continue;
@@ -55,7 +105,27 @@ const normalizeEnv = (env: unknown): Record<string, string> | undefined => {
       normalized[key] = String(value);
       continue;
     }
-    throw new ManifestError(`service.env.${key} must be string | number | boolean`);
+    throw new ManifestError(`${label}.${key} must be string | number | boolean`);
+  }
+  return normalized;
+};
+
+const normalizeEnv = (env: unknown): Record<string, string> | undefined =>
+  normalizeStringTable(env, "service.env");
+
+const normalizeVars = (vars: unknown): Record<string, string> | undefined =>
+  normalizeStringTable(vars, "vars");
+
+const normalizeProfiles = (
+  profiles: unknown,
+): Record<string, Record<string, string>> | undefined => {
+  if (profiles === undefined) return undefined;
+  if (profiles === null || typeof profiles !== "object" || Array.isArray(profiles)) {
+    throw new ManifestError("profiles must be a table of profile tables");
+  }
+  const normalized: Record<string, Record<string, string>> = {};
+  for (const [name, table] of Object.entries(profiles as Record<string, unknown>)) {
+    normalized[name] = normalizeStringTable(table, `profiles.${name}`) ?? {};
   }
   return normalized;
 };
@@ -80,7 +150,24 @@ const normalizeDockerConfig = (docker: unknown): AppDockerConfig | undefined =>
   return { enabled };
 };
 
-const normalizeApp = (app: unknown): AppConfig | undefined => {
+const normalizeKeys = (keys: unknown): AppKeysConfig | undefined => {
+  if (keys === undefined) return undefined;
+  if (keys === null || typeof keys !== "object" || Array.isArray(keys)) {
+    throw new ManifestError("app.keys must be a table of panel tables");
+  }
+  const normalized: AppKeysConfig = {};
+  for (const [panel, table] of Object.entries(keys as Record<string, unknown>)) {
+    if (table === null || typeof table !== "object" || Array.isArray(table)) {
+      throw new ManifestError(`app.keys.${panel} must be a table of string values`);
+    }
+    normalized[panel as PanelId] = table as Record<string, string>;
+  }
+  const error = findKeymapError(normalized);
+  if (error) throw new ManifestError(error);
+  return Object.keys(normalized).length > 0 ? normalized : undefined;
+};
+
+export const normalizeApp = (app: unknown): AppConfig | undefined => {
   if (app === undefined) return undefined;
   if (app === null || typeof app !== "object" || Array.isArray(app)) {
     throw new ManifestError("app must be a table");
@@ -92,9 +179,103 @@ const normalizeApp = (app: unknown): AppConfig | undefined => {
   }
 
   const docker = normalizeDockerConfig((app as { docker?: unknown }).docker);
-  if (!docker) return undefined;
+  const confirmStop = (app as { confirm_stop?: unknown }).confirm_stop;
+  if (confirmStop !== undefined && typeof confirmStop !== "boolean") {
+    throw new ManifestError("app.confirm_stop must be a boolean");
+  }
+  const logLines = (app as { log_lines?: unknown }).log_lines;
+  if (
+    logLines !== undefined &&
+    (typeof logLines !== "number" || !Number.isInteger(logLines) || logLines < 1)
+  ) {
+    throw new ManifestError("app.log_lines must be a positive integer");
+  }
+  const resourceHistory = (app as { resource_history?: unknown }).resource_history;
+  if (
+    resourceHistory !== undefined &&
+    (typeof resourceHistory !== "number" ||
+      !Number.isInteger(resourceHistory) ||
+      resourceHistory < 1)
+  ) {
+    throw new ManifestError("app.resource_history must be a positive integer");
+  }
+  const logTimestamps = (app as { log_timestamps?: unknown }).log_timestamps;
+  if (
+    logTimestamps !== undefined &&
+    (typeof logTimestamps !== "string" || !validLogTimestampFormats.has(logTimestamps))
+  ) {
+    throw new ManifestError("app.log_timestamps must be one of clock | precise | relative");
+  }
+  const metrics = (app as { metrics?: unknown }).metrics;
+  if (metrics !== undefined && (typeof metrics !== "string" || !parseListenAddress(metrics))) {
+    throw new ManifestError('app.metrics must be a listen address such as "127.0.0.1:9464"');
+  }
+  const pollInterval = (app as { poll_interval?: unknown }).poll_interval;
+  if (pollInterval !== undefined) {
+    const intervalMs =
+      typeof pollInterval === "string" || typeof pollInterval === "number"
+        ? parseDuration(pollInterval)
+        : null;
+    if (intervalMs === null || intervalMs < MIN_POLL_INTERVAL_MS) {
+      throw new ManifestError('app.poll_interval must be a duration of at least "100ms"');
+    }
+  }
+  const keys = normalizeKeys((app as { keys?: unknown }).keys);
+  const theme = (app as { theme?: unknown }).theme;
+  if (theme !== undefined && (typeof theme !== "string" || !validThemes.has(theme))) {
+    throw new ManifestError("app.theme must be one of auto | dark | light | high-contrast | mono");
+  }
+  if (
+    !docker &&
+    confirmStop === undefined &&
+    logLines === undefined &&
+    resourceHistory === undefined &&
+    logTimestamps === undefined &&
+    metrics === undefined &&
+    pollInterval === undefined &&
+    !keys &&
+    theme === undefined
+  ) {
+    return undefined;
+  }
+
+  return {
+    docker,
+    confirm_stop: confirmStop,
+    log_lines: logLines,
+    resource_history: resourceHistory,
+    log_timestamps: logTimestamps as LogTimestampFormat | undefined,
+    metrics,
+    poll_interval: pollInterval as string | number | undefined,
+    keys,
+    theme: theme as AppTheme | undefined,
+  };
+};
+
+type ManifestMigration = (raw: RawManifest) => RawManifest;
+
+// Keyed by the version a migration upgrades from; each step returns the next version's shape.
+const manifestMigrations: Record<number, ManifestMigration> = {};
+
+// Manifests without a version predate the key and are treated as version 1.
+const migrateManifest = (raw: RawManifest): RawManifest => {
+  const declared = raw.version ?? 1;
+  if (typeof declared !== "number" || !Number.isInteger(declared) || declared < 1) {
+    throw new ManifestError("version must be a positive integer");
+  }
+  if (declared > MANIFEST_VERSION) {
+    throw new ManifestError(`unsupported manifest version ${declared}, please upgrade stasium`);
+  }
 
-  return { docker };
+  let migrated = raw;
+  for (let version = declared; version < MANIFEST_VERSION; version += 1) {
+    const migrate = manifestMigrations[version];
+    if (!migrate) {
+      throw new ManifestError(`no migration from manifest version ${version}`);
+    }
+    migrated = migrate(migrated);
+  }
+  return { ...migrated, version: MANIFEST_VERSION };
 };
 
 const normalizeService = (raw: ServiceConfig, index: number): ServiceConfig => {
@@ -129,6 +310,30 @@ const normalizeService = (raw: ServiceConfig, index: number): ServiceConfig => {
     }
   }
 
+  if (raw.profiles !== undefined) {
+    if (!Array.isArray(raw.profiles) || raw.profiles.some((item) => typeof item !== "string")) {
+      throw new ManifestError(`service[${index}].profiles must be string[]`);
+    }
+  }
+
+  if (raw.enabled !== undefined && typeof raw.enabled !== "boolean") {
+    throw new ManifestError(`service[${index}].enabled must be a boolean`);
+  }
+
+  if (raw.oneshot !== undefined && typeof raw.oneshot !== "boolean") {
+    throw new ManifestError(`service[${index}].oneshot must be a boolean`);
+  }
+
+  if (raw.oneshot && raw.restart_policy === "always") {
+    throw new ManifestError(`service[${index}] is oneshot and cannot use restart_policy always`);
+  }
+
+  if (raw.env_file !== undefined) {
+    if (!Array.isArray(raw.env_file) || raw.env_file.some((item) => typeof item !== "string")) {
+      throw new ManifestError(`service[${index}].env_file must be string[]`);
+    }
+  }
+
   if (raw.restart_policy !== undefined) {
     if (typeof raw.restart_policy !== "string" || !validRestartPolicies.has(raw.restart_policy)) {
       throw new ManifestError(
@@ -137,6 +342,64 @@ const normalizeService = (raw: ServiceConfig, index: number): ServiceConfig => {
     }
   }
 
+  if (raw.restart_limit !== undefined) {
+    if (!Number.isInteger(raw.restart_limit) || raw.restart_limit < 1) {
+      throw new ManifestError(`service[${index}].restart_limit must be a positive integer`);
+    }
+  }
+
+  for (const key of ["cpu_alert", "mem_alert"] as const) {
+    const value = raw[key];
+    if (value !== undefined && (typeof value !== "number" || !(value > 0))) {
+      throw new ManifestError(`service[${index}].${key} must be a positive number`);
+    }
+  }
+
+  if (raw.restart_window !== undefined) {
+    const windowMs =
+      typeof raw.restart_window === "string" || typeof raw.restart_window === "number"
+        ? parseDuration(raw.restart_window)
+        : null;
+    if (windowMs === null || windowMs <= 0) {
+      throw new ManifestError(
+        `service[${index}].restart_window must be a duration such as "60s" or "2m"`,
+      );
+    }
+  }
+
+  if (raw.start_delay !== undefined) {
+    const delayMs =
+      typeof raw.start_delay === "string" || typeof raw.start_delay === "number"
+        ? parseDuration(raw.start_delay)
+        : null;
+    if (delayMs === null) {
+      throw new ManifestError(
+        `service[${index}].start_delay must be a duration such as "2s" or "500ms"`,
+      );
+    }
+  }
+
+  if (raw.timeout !== undefined) {
+    if (!raw.oneshot) {
+      throw new ManifestError(`service[${index}].timeout only applies to oneshot services`);
+    }
+    const timeoutMs =
+      typeof raw.timeout === "string" || typeof raw.timeout === "number"
+        ? parseDuration(raw.timeout)
+        : null;
+    if (timeoutMs === null || timeoutMs <= 0) {
+      throw new ManifestError(`service[${index}].timeout must be a duration such as "5m" or "30s"`);
+    }
+  }
+
+  if (raw.reload_signal !== undefined) {
+    if (typeof raw.reload_signal !== "string" || !validReloadSignals.has(raw.reload_signal)) {
+      throw new ManifestError(
+        `service[${index}].reload_signal must be one of SIGHUP | SIGUSR1 | SIGUSR2`,
+      );
+    }
+  }
+
   const env = normalizeEnv(raw.env);
 
   return {
@@ -144,13 +407,86 @@ const normalizeService = (raw: ServiceConfig, index: number): ServiceConfig => {
     command: raw.command,
     working_dir: raw.working_dir,
     env,
+    env_file: raw.env_file,
     restart_policy: raw.restart_policy,
+    restart_limit: raw.restart_limit,
+    restart_window: raw.restart_window,
+    start_delay: raw.start_delay,
+    cpu_alert: raw.cpu_alert,
+    mem_alert: raw.mem_alert,
+    reload_signal: raw.reload_signal,
     depends_on: raw.depends_on,
+    profiles: raw.profiles,
+    enabled: raw.enabled,
+    oneshot: raw.oneshot,
+    timeout: raw.timeout,
   };
 };
 
-export const loadManifest = async (path?: string): Promise<Manifest> => {
-  const manifestPath = path ?? DEFAULT_MANIFEST;
+// The selected profile's table overlays [vars], so interpolation sees the profile's values.
+export const getManifestVars = (
+  manifest: Pick<Manifest, "vars" | "profiles" | "profile">,
+): Record<string, string> | undefined => {
+  const overlay = manifest.profile ? manifest.profiles?.[manifest.profile] : undefined;
+  return overlay ? { ...manifest.vars, ...overlay } : manifest.vars;
+};
+
+// Untagged services always run; tagged ones only when one of their profiles is selected.
+export const isActiveInProfile = (service: ServiceConfig, profile: string | undefined): boolean =>
+  !service.profiles ||
+  service.profiles.length === 0 ||
+  (profile !== undefined && service.profiles.includes(profile));
+
+const collectInterpolationWarnings = (
+  services: ServiceConfig[],
+  root: string,
+  vars: Record<string, string> | undefined,
+): string[] => {
+  const warnings: string[] = [];
+  const unknownVars = new Set<string>();
+  const context = createInterpolationContext(root, vars, unknownVars);
+  if (unknownVars.size > 0) {
+    warnings.push(`vars reference unknown variables: ${[...unknownVars].join(", ")}`);
+  }
+
+  for (const service of services) {
+    const { unknown } = interpolateService(service, context);
+    if (unknown.length === 0) continue;
+    warnings.push(`service ${service.name} references unknown variables: ${unknown.join(", ")}`);
+  }
+
+  return warnings;
+};
+
+const normalizeInclude = (include: unknown): string[] | undefined => {
+  if (include === undefined) return undefined;
+  if (
+    !Array.isArray(include) ||
+    include.some((entry) => typeof entry !== "string" || entry.trim().length === 0)
+  ) {
+    throw new ManifestError("include must be an array of file paths");
+  }
+  return include as string[];
+};
+
+// Later definitions replace earlier ones with the same name.
+const mergeServices = (base: ServiceConfig[], overrides: ServiceConfig[]): ServiceConfig[] => {
+  const names = new Set(overrides.map((service) => service.name));
+  return [...base.filter((service) => !names.has(service.name)), ...overrides];
+};
+
+interface ManifestTree {
+  app?: AppConfig;
+  vars?: Record<string, string>;
+  includes?: ManifestIncludes;
+  profiles?: Record<string, Record<string, string>>;
+  services: ServiceConfig[];
+}
+
+// Includes resolve relative to the file naming them and are merged before interpolation runs.
+// The including file wins on service and var name conflicts. Included [app] and [profiles]
+// tables are ignored: only the root file's settings and profiles apply.
+const loadManifestTree = async (manifestPath: string, chain: string[]): Promise<ManifestTree> => {
   const file = Bun.file(manifestPath);
   if (!(await file.exists())) {
     throw new ManifestError(`Manifest not found: ${manifestPath}`);
@@ -164,36 +500,191 @@ export const loadManifest = async (path?: string): Promise<Manifest> => {
     throw new ManifestError(`Invalid TOML: ${getErrorMessage(error)}`);
   }
 
+  parsed = migrateManifest(parsed);
+
   const services = parsed.service ?? [];
   if (!Array.isArray(services)) {
     throw new ManifestError("service must be an array of tables");
   }
 
   const app = normalizeApp(parsed.app);
-  const normalized = services.map((service, index) => normalizeService(service, index));
+  const vars = normalizeVars(parsed.vars);
+  const include = normalizeInclude(parsed.include);
+  const profiles = normalizeProfiles(parsed.profiles);
+  const own = services.map((service, index) => normalizeService(service, index));
+  if (!include) return { app, vars, profiles, services: own };
+
+  const resolvedPath = resolve(manifestPath);
+  const nextChain = [...chain, resolvedPath];
+  let includedServices: ServiceConfig[] = [];
+  let includedVars: Record<string, string> = {};
+  for (const entry of include) {
+    const includePath = resolve(dirname(resolvedPath), entry);
+    if (nextChain.includes(includePath)) {
+      throw new ManifestError(`include cycle: ${[...nextChain, includePath].join(" -> ")}`);
+    }
 
+    let tree: ManifestTree;
+    try {
+      tree = await loadManifestTree(includePath, nextChain);
+    } catch (error) {
+      throw new ManifestError(`include ${entry}: ${getErrorMessage(error)}`);
+    }
+    includedServices = mergeServices(includedServices, tree.services);
+    includedVars = { ...includedVars, ...tree.vars };
+  }
+
+  const ownNames = new Set(own.map((service) => service.name));
+  const mergedVars = { ...includedVars, ...vars };
+  return {
+    app,
+    vars: Object.keys(mergedVars).length > 0 ? mergedVars : undefined,
+    profiles,
+    includes: {
+      paths: include,
+      names: includedServices.map((service) => service.name),
+      services: includedServices.filter((service) => !ownNames.has(service.name)),
+      vars: Object.fromEntries(
+        Object.entries(includedVars).filter(([key]) => vars?.[key] === undefined),
+      ),
+    },
+    services: mergeServices(includedServices, own),
+  };
+};
+
+const assertValidGraph = (services: ServiceConfig[], context?: string): void => {
   try {
-    validateServiceGraph(normalized);
+    validateServiceGraph(services);
   } catch (error) {
     if (error instanceof ServiceGraphError) {
-      throw new ManifestError(error.message);
+      throw new ManifestError(context ? `${context}: ${error.message}` : error.message);
     }
     throw error;
   }
+};
+
+export const loadManifest = async (path?: string, profile?: string): Promise<Manifest> => {
+  const manifestPath = path ?? DEFAULT_MANIFEST;
+  const tree = await loadManifestTree(manifestPath, []);
+  const { app, vars, includes, profiles } = tree;
+  assertValidGraph(tree.services);
+
+  if (profile !== undefined) {
+    const known =
+      profiles?.[profile] !== undefined ||
+      tree.services.some((service) => service.profiles?.includes(profile));
+    if (!known) {
+      throw new ManifestError(`unknown profile: ${profile}`);
+    }
+  }
+
+  const normalized = tree.services.filter(
+    (service) => service.enabled !== false && isActiveInProfile(service, profile),
+  );
+  const inactiveServices = tree.services.filter((service) => !normalized.includes(service));
+  const disabled = new Set(
+    tree.services.filter((service) => service.enabled === false).map((service) => service.name),
+  );
+  for (const service of normalized) {
+    const dependency = service.depends_on?.find((name) => disabled.has(name));
+    if (dependency) {
+      throw new ManifestError(`service ${service.name} depends on disabled service ${dependency}`);
+    }
+  }
+  assertValidGraph(normalized, profile ? `profile ${profile}` : "without a profile");
+
+  const resolvedPath = resolve(manifestPath);
+  const warnings = collectInterpolationWarnings(
+    normalized,
+    dirname(resolvedPath),
+    getManifestVars({ vars, profiles, profile }),
+  );
 
   return {
     app,
+    vars,
+    includes,
+    profiles,
+    profile,
+    inactiveServices: inactiveServices.length > 0 ? inactiveServices : undefined,
+    serviceOrder: tree.services.map((service) => service.name),
     services: normalized,
-    path: resolve(manifestPath),
+    path: resolvedPath,
+    warnings,
   };
 };
 
 const escapeToml = (value: string): string => value.replace(/\\/g, "\\\\").replace(/"/g, '\\"');
 
 const renderAppToml = (app?: AppConfig): string[] => {
-  if (app?.docker?.enabled === undefined) return [];
+  const lines: string[] = [];
+  if (
+    app?.confirm_stop !== undefined ||
+    app?.log_lines !== undefined ||
+    app?.resource_history !== undefined ||
+    app?.log_timestamps !== undefined ||
+    app?.metrics !== undefined ||
+    app?.poll_interval !== undefined ||
+    app?.theme !== undefined
+  ) {
+    lines.push("[app]");
+    if (app.confirm_stop !== undefined) {
+      lines.push(`confirm_stop = ${app.confirm_stop ? "true" : "false"}`);
+    }
+    if (app.log_lines !== undefined) lines.push(`log_lines = ${app.log_lines}`);
+    if (app.resource_history !== undefined) {
+      lines.push(`resource_history = ${app.resource_history}`);
+    }
+    if (app.log_timestamps !== undefined) {
+      lines.push(`log_timestamps = "${app.log_timestamps}"`);
+    }
+    if (app.metrics !== undefined) lines.push(`metrics = "${escapeToml(app.metrics)}"`);
+    if (app.poll_interval !== undefined) {
+      const interval =
+        typeof app.poll_interval === "number"
+          ? String(app.poll_interval)
+          : `"${escapeToml(app.poll_interval)}"`;
+      lines.push(`poll_interval = ${interval}`);
+    }
+    if (app.theme !== undefined) lines.push(`theme = "${app.theme}"`);
+  }
+  if (app?.docker?.enabled !== undefined) {
+    if (lines.length > 0) lines.push("");
+    lines.push("[app.docker]", `enabled = ${app.docker.enabled ? "true" : "false"}`);
+  }
+  for (const [panel, bindings] of Object.entries(app?.keys ?? {})) {
+    if (!bindings || Object.keys(bindings).length === 0) continue;
+    if (lines.length > 0) lines.push("");
+    lines.push(`[app.keys.${panel}]`);
+    for (const [label, key] of Object.entries(bindings)) {
+      lines.push(`"${escapeToml(label)}" = "${escapeToml(key)}"`);
+    }
+  }
+  return lines;
+};
+
+const renderVarsToml = (vars?: Record<string, string>): string[] => {
+  if (!vars || Object.keys(vars).length === 0) return [];
+
+  const lines = ["[vars]"];
+  for (const [key, value] of Object.entries(vars)) {
+    lines.push(`"${escapeToml(key)}" = "${escapeToml(value)}"`);
+  }
+  return lines;
+};
 
-  return ["[app.docker]", `enabled = ${app.docker.enabled ? "true" : "false"}`];
+const renderProfilesToml = (profiles?: Record<string, Record<string, string>>): string[] => {
+  if (!profiles) return [];
+
+  const lines: string[] = [];
+  for (const [name, vars] of Object.entries(profiles)) {
+    if (lines.length > 0) lines.push("");
+    lines.push(`[profiles."${escapeToml(name)}"]`);
+    for (const [key, value] of Object.entries(vars)) {
+      lines.push(`"${escapeToml(key)}" = "${escapeToml(value)}"`);
+    }
+  }
+  return lines;
 };
 
 const renderServiceToml = (service: ServiceConfig): string => {
@@ -210,10 +701,53 @@ const renderServiceToml = (service: ServiceConfig): string => {
   if (service.restart_policy) {
     lines.push(`restart_policy = "${service.restart_policy}"`);
   }
+  if (service.restart_limit !== undefined) {
+    lines.push(`restart_limit = ${service.restart_limit}`);
+  }
+  if (service.restart_window !== undefined) {
+    const window =
+      typeof service.restart_window === "number"
+        ? String(service.restart_window)
+        : `"${escapeToml(service.restart_window)}"`;
+    lines.push(`restart_window = ${window}`);
+  }
+  if (service.start_delay !== undefined) {
+    const delay =
+      typeof service.start_delay === "number"
+        ? String(service.start_delay)
+        : `"${escapeToml(service.start_delay)}"`;
+    lines.push(`start_delay = ${delay}`);
+  }
+  if (service.cpu_alert !== undefined) lines.push(`cpu_alert = ${service.cpu_alert}`);
+  if (service.mem_alert !== undefined) lines.push(`mem_alert = ${service.mem_alert}`);
+  if (service.reload_signal) {
+    lines.push(`reload_signal = "${service.reload_signal}"`);
+  }
   if (service.depends_on && service.depends_on.length > 0) {
     const deps = service.depends_on.map((d) => `"${escapeToml(d)}"`).join(", ");
     lines.push(`depends_on = [${deps}]`);
   }
+  if (service.env_file && service.env_file.length > 0) {
+    const files = service.env_file.map((f) => `"${escapeToml(f)}"`).join(", ");
+    lines.push(`env_file = [${files}]`);
+  }
+  if (service.profiles && service.profiles.length > 0) {
+    const profiles = service.profiles.map((p) => `"${escapeToml(p)}"`).join(", ");
+    lines.push(`profiles = [${profiles}]`);
+  }
+  if (service.enabled !== undefined) {
+    lines.push(`enabled = ${service.enabled ? "true" : "false"}`);
+  }
+  if (service.oneshot !== undefined) {
+    lines.push(`oneshot = ${service.oneshot ? "true" : "false"}`);
+  }
+  if (service.timeout !== undefined) {
+    const timeout =
+      typeof service.timeout === "number"
+        ? String(service.timeout)
+        : `"${escapeToml(service.timeout)}"`;
+    lines.push(`timeout = ${timeout}`);
+  }
   if (service.env && Object.keys(service.env).length > 0) {
     lines.push("[service.env]");
     for (const [key, value] of Object.entries(service.env)) {
@@ -223,17 +757,44 @@ const renderServiceToml = (service: ServiceConfig): string => {
   return lines.join("\n");
 };
 
-export const renderManifest = (services: ServiceConfig[], app?: AppConfig): string => {
+export interface ManifestExtras {
+  include?: string[];
+  profiles?: Record<string, Record<string, string>>;
+}
+
+export const renderManifest = (
+  services: ServiceConfig[],
+  app?: AppConfig,
+  vars?: Record<string, string>,
+  { include, profiles }: ManifestExtras = {},
+): string => {
   const lines: string[] = [];
   lines.push("# stasium.toml");
   lines.push("");
 
+  if (include && include.length > 0) {
+    lines.push(`include = [${include.map((path) => `"${escapeToml(path)}"`).join(", ")}]`);
+    lines.push("");
+  }
+
   const appLines = renderAppToml(app);
   if (appLines.length > 0) {
     lines.push(...appLines);
     lines.push("");
   }
 
+  const varsLines = renderVarsToml(vars);
+  if (varsLines.length > 0) {
+    lines.push(...varsLines);
+    lines.push("");
+  }
+
+  const profileLines = renderProfilesToml(profiles);
+  if (profileLines.length > 0) {
+    lines.push(...profileLines);
+    lines.push("");
+  }
+
   if (services.length === 0) {
     lines.push("# No services configured. Add [[service]] blocks below.");
     lines.push("#");
@@ -278,11 +839,81 @@ export const parseServiceBlock = (toml: string): ServiceConfig => {
   return normalizeService(raw, 0);
 };
 
+export interface SaveManifestOptions {
+  includes?: ManifestIncludes;
+  profiles?: Record<string, Record<string, string>>;
+  inactiveServices?: ServiceConfig[];
+  order?: string[];
+}
+
+// Known services keep their place in order; new ones follow in the order given.
+const orderServices = (services: ServiceConfig[], order: string[] = []): ServiceConfig[] => {
+  const rank = new Map(order.map((name, index) => [name, index]));
+  return services
+    .map((service, index) => ({
+      service,
+      position: rank.get(service.name) ?? order.length + index,
+    }))
+    .sort((left, right) => left.position - right.position)
+    .map(({ service }) => service);
+};
+
+// Services and vars that still match their included definition are left to the included file;
+// edited ones are written here, where they override it. Services outside the selected profile
+// are written back unchanged.
 export const saveManifest = async (
   path: string,
   services: ServiceConfig[],
   app?: AppConfig,
-): Promise<void> => {
-  const contents = renderManifest(services, app);
+  vars?: Record<string, string>,
+  { includes, profiles, inactiveServices = [], order }: SaveManifestOptions = {},
+): Promise<string> => {
+  const included = new Map(
+    (includes?.services ?? []).map((service) => [service.name, JSON.stringify(service)]),
+  );
+  const ownServices = orderServices([...services, ...inactiveServices], order).filter(
+    (service) => included.get(service.name) !== JSON.stringify(service),
+  );
+  const ownVars = vars
+    ? Object.fromEntries(
+        Object.entries(vars).filter(([key, value]) => includes?.vars[key] !== value),
+      )
+    : undefined;
+  const contents = renderManifest(ownServices, app, ownVars, {
+    include: includes?.paths,
+    profiles,
+  });
   await Bun.write(path, contents);
+  return contents;
+};
+
+// Flips one service's enabled flag on disk; includes, profiles and the other services are kept.
+export const setServiceEnabled = async (
+  path: string,
+  name: string,
+  enabled: boolean,
+): Promise<void> => {
+  const manifest = await loadManifest(path);
+  const services = [...manifest.services, ...(manifest.inactiveServices ?? [])];
+  if (!services.some((service) => service.name === name)) {
+    throw new ManifestError(`unknown service: ${name}`);
+  }
+
+  if (!enabled) {
+    const dependents = services
+      .filter((service) => service.enabled !== false && service.depends_on?.includes(name))
+      .map((service) => service.name);
+    if (dependents.length > 0) {
+      throw new ManifestError(`cannot disable ${name}: ${dependents.join(", ")} depend on it`);
+    }
+  }
+
+  const next = services.map((service) =>
+    service.name === name ? { ...service, enabled: enabled ? undefined : false } : service,
+  );
+  await saveManifest(path, next, manifest.app, manifest.vars, {
+    includes: manifest.includes,
+    profiles: manifest.profiles,
+    order: manifest.serviceOrder,
+  });
 };
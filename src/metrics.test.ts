@@ -0,0 +1,60 @@
+import { describe, expect, test } from "bun:test";
+import { LogBuffer } from "./log-buffer";
+import { parseListenAddress, renderMetrics } from "./metrics";
+import type { ServiceView } from "./service-manager";
+
+const makeView = (name: string, overrides: Partial<ServiceView> = {}): ServiceView => ({
+  name,
+  state: "RUNNING",
+  lastExitCode: null,
+  restartCount: 0,
+  restartInMs: null,
+  cpuPercent: null,
+  memBytes: null,
+  cpuHistory: [],
+  memHistory: [],
+  alerts: [],
+  pids: [],
+  ports: [],
+  completed: false,
+  log: new LogBuffer(10),
+  config: { name, command: "true" },
+  ...overrides,
+});
+
+describe("parseListenAddress", () => {
+  test("defaults to loopback when the host is omitted", () => {
+    expect(parseListenAddress("9464")).toEqual({ hostname: "127.0.0.1", port: 9464 });
+    expect(parseListenAddress(":9464")).toEqual({ hostname: "127.0.0.1", port: 9464 });
+    expect(parseListenAddress("0.0.0.0:9464")).toEqual({ hostname: "0.0.0.0", port: 9464 });
+  });
+
+  test("rejects missing or out-of-range ports", () => {
+    expect(parseListenAddress("localhost")).toBeNull();
+    expect(parseListenAddress("localhost:0")).toBeNull();
+    expect(parseListenAddress("localhost:70000")).toBeNull();
+  });
+});
+
+describe("renderMetrics", () => {
+  test("renders service, action and reload series", () => {
+    const text = renderMetrics({
+      services: [
+        makeView("api", { restartCount: 2, cpuPercent: 12.5, memBytes: 2048 }),
+        makeView("worker", { state: "FAILED" }),
+      ],
+      actions: { restart: 3 },
+      manifestReloads: { ok: 1, failed: 0 },
+      uptimeSeconds: 42.7,
+    });
+
+    expect(text).toContain("stasium_uptime_seconds 42\n");
+    expect(text).toContain('stasium_service_up{service="api"} 1\n');
+    expect(text).toContain('stasium_service_up{service="worker"} 0\n');
+    expect(text).toContain('stasium_service_restarts_total{service="api"} 2\n');
+    expect(text).toContain('stasium_service_cpu_percent{service="api"} 12.5\n');
+    expect(text).not.toContain('stasium_service_cpu_percent{service="worker"}');
+    expect(text).toContain('stasium_actions_total{action="restart"} 3\n');
+    expect(text).toContain('stasium_manifest_reloads_total{result="ok"} 1\n');
+  });
+});
@@ -0,0 +1,99 @@
+import type { ServiceView } from "./service-manager";
+
+export interface MetricsSnapshot {
+  services: ServiceView[];
+  actions: Record<string, number>;
+  manifestReloads: { ok: number; failed: number };
+  uptimeSeconds: number;
+}
+
+export interface ListenAddress {
+  hostname: string;
+  port: number;
+}
+
+// Accepts "host:port", ":port" or a bare port; an omitted host binds to loopback only.
+export const parseListenAddress = (value: string): ListenAddress | null => {
+  const separator = value.lastIndexOf(":");
+  const host = separator === -1 ? "" : value.slice(0, separator);
+  const portText = separator === -1 ? value : value.slice(separator + 1);
+  if (!/^\d+$/.test(portText)) return null;
+
+  const port = Number(portText);
+  if (port < 1 || port > 65_535) return null;
+  return { hostname: host || "127.0.0.1", port };
+};
+
+const escapeLabel = (value: string): string =>
+  value.replace(/\\/g, "\\\\").replace(/"/g, '\\"').replace(/\n/g, "\\n");
+
+const family = (name: string, type: "gauge" | "counter", help: string): string[] => [
+  `# HELP ${name} ${help}`,
+  `# TYPE ${name} ${type}`,
+];
+
+export const renderMetrics = (snapshot: MetricsSnapshot): string => {
+  const lines: string[] = [];
+
+  lines.push(...family("stasium_uptime_seconds", "gauge", "Seconds since stasium started."));
+  lines.push(`stasium_uptime_seconds ${Math.floor(snapshot.uptimeSeconds)}`);
+
+  lines.push(...family("stasium_service_up", "gauge", "1 when the service is RUNNING."));
+  for (const view of snapshot.services) {
+    lines.push(
+      `stasium_service_up{service="${escapeLabel(view.name)}"} ${view.state === "RUNNING" ? 1 : 0}`,
+    );
+  }
+
+  lines.push(...family("stasium_service_restarts_total", "counter", "Service restarts."));
+  for (const view of snapshot.services) {
+    lines.push(
+      `stasium_service_restarts_total{service="${escapeLabel(view.name)}"} ${view.restartCount}`,
+    );
+  }
+
+  lines.push(...family("stasium_service_cpu_percent", "gauge", "Sampled CPU usage."));
+  for (const view of snapshot.services) {
+    if (view.cpuPercent === null) continue;
+    const label = escapeLabel(view.name);
+    lines.push(`stasium_service_cpu_percent{service="${label}"} ${view.cpuPercent}`);
+  }
+
+  lines.push(...family("stasium_service_memory_bytes", "gauge", "Sampled resident memory."));
+  for (const view of snapshot.services) {
+    if (view.memBytes === null) continue;
+    const label = escapeLabel(view.name);
+    lines.push(`stasium_service_memory_bytes{service="${label}"} ${view.memBytes}`);
+  }
+
+  lines.push(...family("stasium_actions_total", "counter", "Lifecycle actions invoked."));
+  for (const [action, count] of Object.entries(snapshot.actions)) {
+    lines.push(`stasium_actions_total{action="${escapeLabel(action)}"} ${count}`);
+  }
+
+  lines.push(...family("stasium_manifest_reloads_total", "counter", "Manifest reloads."));
+  lines.push(`stasium_manifest_reloads_total{result="ok"} ${snapshot.manifestReloads.ok}`);
+  lines.push(`stasium_manifest_reloads_total{result="failed"} ${snapshot.manifestReloads.failed}`);
+
+  return `${lines.join("\n")}\n`;
+};
+
+export const startMetricsServer = (
+  address: ListenAddress,
+  snapshot: () => MetricsSnapshot,
+): { stop: () => void } => {
+  const server = Bun.serve({
+    hostname: address.hostname,
+    port: address.port,
+    fetch(request) {
+      if (new URL(request.url).pathname !== "/metrics") {
+        return new Response("not found\n", { status: 404 });
+      }
+      return new Response(renderMetrics(snapshot()), {
+        headers: { "content-type": "text/plain; version=0.0.4" },
+      });
+    },
+  });
+
+  return { stop: () => server.stop(true) };
+};
@@ -22,10 +22,10 @@ const buildPidFileName = (name: string): string => `${sanitizeServiceName(name)}
 
 const getServiceNameFromFile = (path: string): string => basename(path, PID_EXTENSION);
 
-const getPidDir = (cwd: string): string => {
-  const resolved = realpathSync(cwd);
-  return resolve(pidDirRoot, checksum(resolved));
-};
+// Per-project files are keyed by a hash of the project's real path.
+export const getProjectKey = (cwd: string): string => checksum(realpathSync(cwd));
+
+const getPidDir = (cwd: string): string => resolve(pidDirRoot, getProjectKey(cwd));
 
 export const setPidDirRootForTests = (root: string | null): void => {
   pidDirRoot = root ? resolve(root) : resolve(homedir(), ".local", "share", "stasium");
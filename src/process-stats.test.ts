@@ -0,0 +1,131 @@
+import { describe, expect, test } from "bun:test";
+import { mkdir, mkdtemp, rm, symlink } from "node:fs/promises";
+import { tmpdir } from "node:os";
+import { join } from "node:path";
+import {
+  ProcessSampler,
+  computeCpuPercent,
+  groupByLeader,
+  parseListeningSockets,
+  parseProcStat,
+  parseSocketInode,
+  parseStatusRssBytes,
+} from "./process-stats";
+
+describe("process stats parsing", () => {
+  test("parses stat lines whose command contains spaces and parentheses", () => {
+    const stat = parseProcStat(
+      "4242 (php (artisan) serve) S 4100 4242 4100 0 -1 4194560 1523 0 0 0 250 50 0 0 20 0 1 0",
+    );
+
+    expect(stat).toEqual({ pid: 4242, ppid: 4100, pgrp: 4242, cpuTicks: 300 });
+  });
+
+  test("rejects malformed stat lines", () => {
+    expect(parseProcStat("not a stat line")).toBeNull();
+  });
+
+  test("reads resident memory from status", () => {
+    const status = ["Name:\tnode", "VmPeak:\t  900000 kB", "VmRSS:\t  123456 kB"].join("\n");
+    expect(parseStatusRssBytes(status)).toBe(123456 * 1024);
+    expect(parseStatusRssBytes("Name:\tkthreadd")).toBe(0);
+  });
+
+  test("computes cpu percent from tick deltas", () => {
+    expect(computeCpuPercent({ ticks: 100, at: 0 }, { ticks: 150, at: 1000 })).toBe(50);
+    expect(computeCpuPercent({ ticks: 100, at: 0 }, { ticks: 300, at: 1000 })).toBe(200);
+    expect(computeCpuPercent({ ticks: 100, at: 1000 }, { ticks: 150, at: 1000 })).toBeNull();
+  });
+
+  test("maps listening socket inodes to ports", () => {
+    const tcp = [
+      "sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode",
+      "0: 0100007F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000 1000 0 51234",
+      "1: 0100007F:1F90 0100007F:D431 01 00000000:00000000 00:00000000 00000000 1000 0 51240",
+    ].join("\n");
+    const tcp6 = [
+      "sl local_address remote_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode",
+      `0: ${"0".repeat(32)}:1435 ${"0".repeat(32)}:0000 0A 0:0 0:0 0 1000 0 61001`,
+    ].join("\n");
+
+    expect([...parseListeningSockets(tcp)]).toEqual([[51234, 8080]]);
+    expect([...parseListeningSockets(tcp6)]).toEqual([[61001, 5173]]);
+  });
+
+  test("reads socket inodes from fd links", () => {
+    expect(parseSocketInode("socket:[51234]")).toBe(51234);
+    expect(parseSocketInode("/dev/null")).toBeNull();
+  });
+
+  test("groups processes under their process group leader", () => {
+    const groups = groupByLeader(
+      [
+        { pid: 100, ppid: 1, pgrp: 100, cpuTicks: 10 },
+        { pid: 101, ppid: 100, pgrp: 100, cpuTicks: 20 },
+        { pid: 102, ppid: 101, pgrp: 100, cpuTicks: 30 },
+        { pid: 200, ppid: 1, pgrp: 200, cpuTicks: 5 },
+        { pid: 301, ppid: 1, pgrp: 300, cpuTicks: 5 },
+      ],
+      [100, 300],
+    );
+
+    expect([...groups.keys()]).toEqual([100]);
+    expect(groups.get(100)?.map((stat) => stat.pid)).toEqual([100, 101, 102]);
+  });
+});
+
+const writeProcEntry = async (
+  root: string,
+  pid: number,
+  pgrp: number,
+  ticks: number,
+  rssKb: number,
+  sockets: number[] = [],
+): Promise<void> => {
+  const dir = join(root, String(pid));
+  await mkdir(join(dir, "fd"), { recursive: true });
+  await Bun.write(
+    join(dir, "stat"),
+    `${pid} (node) S 1 ${pgrp} ${pgrp} 0 -1 0 0 0 0 0 ${ticks} 0 0 0 20 0 1 0`,
+  );
+  await Bun.write(join(dir, "status"), `Name:\tnode\nVmRSS:\t${rssKb} kB\n`);
+  for (const [index, inode] of sockets.entries()) {
+    await symlink(`socket:[${inode}]`, join(dir, "fd", String(index + 3)));
+  }
+};
+
+describe("ProcessSampler", () => {
+  test("reads a synthetic proc tree from a configured root", async () => {
+    const root = await mkdtemp(join(tmpdir(), "stasium-proc-"));
+    let now = 0;
+
+    try {
+      await writeProcEntry(root, 100, 100, 100, 1024, [7001]);
+      await writeProcEntry(root, 101, 100, 50, 2048);
+      await writeProcEntry(root, 200, 200, 10, 512);
+      await Bun.write(
+        join(root, "100", "net", "tcp"),
+        [
+          "sl local_address rem_address st tx_queue:rx_queue tr:tm->when ... inode",
+          "0: 00000000:0BB8 00000000:0000 0A 00000000:00000000 00:00000000 00000000 1000 0 7001",
+        ].join("\n"),
+      );
+
+      const sampler = new ProcessSampler({ root, now: () => now });
+      const first = await sampler.sample([100]);
+      expect(first.get(100)).toEqual({
+        cpuPercent: null,
+        memBytes: 3072 * 1024,
+        pids: [100, 101],
+        ports: [3000],
+      });
+
+      now = 1000;
+      await writeProcEntry(root, 101, 100, 100, 2048);
+      const second = await sampler.sample([100]);
+      expect(second.get(100)?.cpuPercent).toBe(50);
+    } finally {
+      await rm(root, { recursive: true, force: true });
+    }
+  });
+});
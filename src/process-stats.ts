@@ -0,0 +1,240 @@
+import { readFile, readdir, readlink } from "node:fs/promises";
+import { join } from "node:path";
+
+export const DEFAULT_PROC_ROOT = "/proc";
+// utime/stime are reported in USER_HZ ticks, which is 100 on every mainstream Linux build.
+const CLOCK_TICKS_PER_SECOND = 100;
+const TCP_LISTEN_STATE = "0A";
+
+export interface ProcStat {
+  pid: number;
+  ppid: number;
+  pgrp: number;
+  cpuTicks: number;
+}
+
+export interface CpuSample {
+  ticks: number;
+  at: number;
+}
+
+export interface ProcessSamplerOptions {
+  // Lets tests use a synthetic tree and containers point at a bind-mounted host /proc.
+  root?: string;
+  now?: () => number;
+}
+
+export interface ProcessUsage {
+  cpuPercent: number | null;
+  memBytes: number;
+  pids: number[];
+  ports: number[];
+}
+
+export const parseProcStat = (text: string): ProcStat | null => {
+  const open = text.indexOf("(");
+  const close = text.lastIndexOf(")");
+  if (open === -1 || close === -1 || close < open) return null;
+
+  // Fields after the command name start at field 3 (state) in proc(5) numbering.
+  const fields = text
+    .slice(close + 1)
+    .trim()
+    .split(/\s+/);
+  const pid = Number.parseInt(text.slice(0, open).trim(), 10);
+  const ppid = Number.parseInt(fields[1] ?? "", 10);
+  const pgrp = Number.parseInt(fields[2] ?? "", 10);
+  const utime = Number.parseInt(fields[11] ?? "", 10);
+  const stime = Number.parseInt(fields[12] ?? "", 10);
+
+  if ([pid, ppid, pgrp, utime, stime].some((value) => !Number.isFinite(value))) return null;
+  return { pid, ppid, pgrp, cpuTicks: utime + stime };
+};
+
+export const parseStatusRssBytes = (text: string): number => {
+  const match = /^VmRSS:\s+(\d+)\s+kB/m.exec(text);
+  if (!match) return 0;
+  return Number.parseInt(match[1] ?? "0", 10) * 1024;
+};
+
+export const computeCpuPercent = (previous: CpuSample, current: CpuSample): number | null => {
+  const elapsedMs = current.at - previous.at;
+  if (elapsedMs <= 0) return null;
+  const ticks = Math.max(0, current.ticks - previous.ticks);
+  return ((ticks / CLOCK_TICKS_PER_SECOND) * 1000 * 100) / elapsedMs;
+};
+
+// Maps socket inodes to local ports for LISTEN entries in /proc/<pid>/net/tcp or tcp6.
+export const parseListeningSockets = (text: string): Map<number, number> => {
+  const sockets = new Map<number, number>();
+  for (const line of text.split("\n").slice(1)) {
+    // sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+    const fields = line.trim().split(/\s+/);
+    if (fields[3] !== TCP_LISTEN_STATE) continue;
+
+    const port = Number.parseInt(fields[1]?.split(":")[1] ?? "", 16);
+    const inode = Number.parseInt(fields[9] ?? "", 10);
+    if (!Number.isFinite(port) || !Number.isFinite(inode) || inode === 0) continue;
+    sockets.set(inode, port);
+  }
+  return sockets;
+};
+
+export const parseSocketInode = (link: string): number | null => {
+  const match = /^socket:\[(\d+)\]$/.exec(link);
+  return match ? Number.parseInt(match[1] ?? "", 10) : null;
+};
+
+// Services are spawned as process group leaders, so a leader's pid doubles as its group id.
+export const groupByLeader = (
+  stats: ProcStat[],
+  leaders: Iterable<number>,
+): Map<number, ProcStat[]> => {
+  const groups = new Map<number, ProcStat[]>();
+  for (const leader of leaders) groups.set(leader, []);
+
+  for (const stat of stats) {
+    groups.get(stat.pgrp)?.push(stat);
+  }
+
+  for (const [leader, members] of groups) {
+    if (!members.some((member) => member.pid === leader)) groups.delete(leader);
+  }
+  return groups;
+};
+
+const readProcFile = async (root: string, pid: number, name: string): Promise<string | null> => {
+  try {
+    return await readFile(join(root, String(pid), name), "utf8");
+  } catch {
+    return null;
+  }
+};
+
+const readSocketInodes = async (root: string, pid: number): Promise<number[]> => {
+  const fdDir = join(root, String(pid), "fd");
+  let fds: string[];
+  try {
+    fds = await readdir(fdDir);
+  } catch {
+    return [];
+  }
+
+  const inodes = await Promise.all(
+    fds.map(async (fd) => {
+      try {
+        return parseSocketInode(await readlink(join(fdDir, fd)));
+      } catch {
+        return null;
+      }
+    }),
+  );
+  return inodes.filter((inode): inode is number => inode !== null);
+};
+
+// Group members share a network namespace, so the leader's tables cover every member's sockets.
+const listListeningPorts = async (
+  root: string,
+  leader: number,
+  members: ProcStat[],
+): Promise<number[]> => {
+  const tables = await Promise.all([
+    readProcFile(root, leader, "net/tcp"),
+    readProcFile(root, leader, "net/tcp6"),
+  ]);
+  const listening = new Map<number, number>();
+  for (const table of tables) {
+    if (!table) continue;
+    for (const [inode, port] of parseListeningSockets(table)) listening.set(inode, port);
+  }
+  if (listening.size === 0) return [];
+
+  const ports = new Set<number>();
+  const memberInodes = await Promise.all(
+    members.map((member) => readSocketInodes(root, member.pid)),
+  );
+  for (const inodes of memberInodes) {
+    for (const inode of inodes) {
+      const port = listening.get(inode);
+      if (port !== undefined) ports.add(port);
+    }
+  }
+  return [...ports].sort((left, right) => left - right);
+};
+
+const listProcStats = async (root: string): Promise<ProcStat[]> => {
+  let entries: string[];
+  try {
+    entries = await readdir(root);
+  } catch {
+    return [];
+  }
+
+  const stats = await Promise.all(
+    entries
+      .filter((entry) => /^\d+$/.test(entry))
+      .map(async (entry) => {
+        const text = await readProcFile(root, Number.parseInt(entry, 10), "stat");
+        return text ? parseProcStat(text) : null;
+      }),
+  );
+  return stats.filter((stat): stat is ProcStat => stat !== null);
+};
+
+export class ProcessSampler {
+  private readonly previous: Map<number, CpuSample> = new Map();
+  private readonly root: string;
+  private readonly now: () => number;
+
+  constructor(options: ProcessSamplerOptions = {}) {
+    this.root = options.root ?? DEFAULT_PROC_ROOT;
+    this.now = options.now ?? Date.now;
+  }
+
+  // Samples every process in each leader's group and sums their usage.
+  async sample(leaders: number[]): Promise<Map<number, ProcessUsage>> {
+    const usage = new Map<number, ProcessUsage>();
+    const wanted = leaders.filter((pid) => Number.isInteger(pid) && pid > 0);
+    const unsupported = process.platform !== "linux" && this.root === DEFAULT_PROC_ROOT;
+    if (unsupported || wanted.length === 0) {
+      this.previous.clear();
+      return usage;
+    }
+
+    const groups = groupByLeader(await listProcStats(this.root), wanted);
+    const at = this.now();
+
+    await Promise.all(
+      [...groups].map(async ([leader, members]) => {
+        const [rss, ports] = await Promise.all([
+          Promise.all(
+            members.map(async (member) => {
+              const status = await readProcFile(this.root, member.pid, "status");
+              return status ? parseStatusRssBytes(status) : 0;
+            }),
+          ),
+          listListeningPorts(this.root, leader, members),
+        ]);
+
+        const current: CpuSample = {
+          ticks: members.reduce((sum, member) => sum + member.cpuTicks, 0),
+          at,
+        };
+        const previous = this.previous.get(leader);
+        this.previous.set(leader, current);
+
+        usage.set(leader, {
+          cpuPercent: previous ? computeCpuPercent(previous, current) : null,
+          memBytes: rss.reduce((sum, bytes) => sum + bytes, 0),
+          pids: members.map((member) => member.pid).sort((left, right) => left - right),
+          ports,
+        });
+      }),
+    );
+
+    for (const leader of this.previous.keys()) {
+      if (!groups.has(leader)) this.previous.delete(leader);
+    }
+    return usage;
+  }
+}
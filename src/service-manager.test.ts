@@ -1,4 +1,8 @@
 import { describe, expect, test } from "bun:test";
+import { mkdtemp, rm } from "node:fs/promises";
+import { tmpdir } from "node:os";
+import { join } from "node:path";
+import { loadDesiredStates, saveDesiredStates } from "./desired-state";
 import { ServiceManager, ServiceManagerError } from "./service-manager";
 import type { ServiceConfig } from "./types";
 
@@ -45,6 +49,23 @@ describe("ServiceManager", () => {
     );
   });
 
+  test("adds a service without starting it until it is started by name", async () => {
+    const manager = new ServiceManager([]);
+
+    try {
+      await manager.addService({
+        name: "api",
+        command: ["bun", "-e", "setInterval(() => {}, 1000)"],
+      });
+      expect(manager.getViews().map((view) => view.state)).toEqual(["STOPPED"]);
+
+      await manager.startNamed("api");
+      expect(manager.getViews().map((view) => view.state)).toEqual(["RUNNING"]);
+    } finally {
+      await manager.stopAll();
+    }
+  });
+
   test("starts dependencies before selected service", async () => {
     const manager = new ServiceManager([
       {
@@ -68,6 +89,126 @@ describe("ServiceManager", () => {
     await manager.stopAll();
   });
 
+  test("waits for a dependency to settle before starting its dependents", async () => {
+    const manager = new ServiceManager([
+      { name: "db", command: ["bun", "-e", "setInterval(() => {}, 1000)"] },
+      {
+        name: "api",
+        command: ["bun", "-e", "setInterval(() => {}, 1000)"],
+        depends_on: ["db"],
+      },
+    ]);
+    const dbStates: string[] = [];
+    manager.onUpdate(() => {
+      const [db, api] = manager.getViews();
+      if (api?.state === "STARTING" && db) dbStates.push(db.state);
+    });
+
+    try {
+      await manager.startAll();
+      expect(dbStates.length).toBeGreaterThan(0);
+      expect(dbStates.every((state) => state === "RUNNING")).toBe(true);
+      expect(manager.getViews().map((view) => view.state)).toEqual(["RUNNING", "RUNNING"]);
+    } finally {
+      await manager.stopAll();
+    }
+  });
+
+  test("runs oneshot dependencies to completion before their dependents", async () => {
+    const manager = new ServiceManager([
+      { name: "migrate", command: ["bun", "-e", "setTimeout(() => {}, 300)"], oneshot: true },
+      { name: "broken", command: ["bun", "-e", "process.exit(2)"], oneshot: true },
+      {
+        name: "api",
+        command: ["bun", "-e", "setInterval(() => {}, 1000)"],
+        depends_on: ["migrate"],
+      },
+      {
+        name: "worker",
+        command: ["bun", "-e", "setInterval(() => {}, 1000)"],
+        depends_on: ["broken"],
+      },
+    ]);
+
+    try {
+      await manager.startAll();
+      const views = new Map(manager.getViews().map((view) => [view.name, view]));
+      expect(views.get("migrate")?.completed).toBe(true);
+      expect(views.get("api")?.state).toBe("RUNNING");
+      expect(views.get("broken")?.state).toBe("FAILED");
+      expect(views.get("worker")?.state).toBe("STOPPED");
+    } finally {
+      await manager.stopAll();
+    }
+  });
+
+  test("fails a oneshot that outlives its timeout and keeps dependents stopped", async () => {
+    const manager = new ServiceManager([
+      {
+        name: "migrate",
+        command: ["bun", "-e", "setInterval(() => {}, 1000)"],
+        oneshot: true,
+        timeout: "300ms",
+      },
+      {
+        name: "api",
+        command: ["bun", "-e", "setInterval(() => {}, 1000)"],
+        depends_on: ["migrate"],
+      },
+    ]);
+
+    try {
+      await manager.startAll();
+      const [migrate, api] = manager.getViews();
+      expect(migrate?.state).toBe("FAILED");
+      expect(migrate?.completed).toBe(false);
+      expect(api?.state).toBe("STOPPED");
+      const lines = migrate?.log.all().map((entry) => entry.line) ?? [];
+      expect(lines).toContain("oneshot did not finish within 300ms, stopped");
+      expect(manager.getServicePids()).toHaveLength(0);
+    } finally {
+      await manager.stopAll();
+    }
+  });
+
+  test("keeps dependents of a failed oneshot stopped on restart and reload", async () => {
+    const broken: ServiceConfig = {
+      name: "broken",
+      command: ["bun", "-e", "process.exit(2)"],
+      oneshot: true,
+    };
+    const worker: ServiceConfig = {
+      name: "worker",
+      command: ["bun", "-e", "setInterval(() => {}, 1000)"],
+      depends_on: ["broken"],
+    };
+    const manager = new ServiceManager([broken, worker]);
+
+    try {
+      await manager.startAll();
+      manager.setSelectedIndex(1);
+      await manager.restartSelected();
+      expect(manager.getSelectedView()?.state).toBe("STOPPED");
+      expect(manager.getSelectedView()?.restartCount).toBe(0);
+
+      const summary = await manager.reload([
+        broken,
+        { ...worker, command: ["bun", "-e", "setInterval(() => {}, 500)"] },
+        { ...worker, name: "extra" },
+      ]);
+      expect(summary.updated).toEqual(["worker"]);
+      expect(summary.added).toEqual(["extra"]);
+      expect(manager.getViews().map((view) => [view.name, view.state])).toEqual([
+        ["broken", "FAILED"],
+        ["worker", "STOPPED"],
+        ["extra", "STOPPED"],
+      ]);
+      expect(manager.getServicePids()).toHaveLength(0);
+    } finally {
+      await manager.stopAll();
+    }
+  });
+
   test("stops selected dependency and its dependents", async () => {
     const manager = new ServiceManager([
       {
@@ -92,6 +233,155 @@ describe("ServiceManager", () => {
     expect(stopped).toBe(true);
   });
 
+  test("restarts every service with fresh processes", async () => {
+    const keepAlive = ["bun", "-e", "setInterval(() => {}, 1000)"];
+    const manager = new ServiceManager([
+      { name: "db", command: keepAlive },
+      { name: "api", command: keepAlive, depends_on: ["db"] },
+    ]);
+
+    try {
+      await manager.startAll();
+      const before = manager.getServicePids().map((entry) => entry.pid);
+      expect(before).toHaveLength(2);
+
+      await manager.restartAll();
+      const after = manager.getServicePids();
+      expect(after.map((entry) => entry.name).sort()).toEqual(["api", "db"]);
+      expect(after.some((entry) => before.includes(entry.pid))).toBe(false);
+      expect(manager.getViews().map((view) => view.restartCount)).toEqual([1, 1]);
+    } finally {
+      await manager.stopAll();
+    }
+  });
+
+  test("counts a restart only for services that came back up", async () => {
+    const manager = new ServiceManager([
+      { name: "db", command: ["bun", "-e", "setInterval(() => {}, 1000)"] },
+      { name: "broken", command: ["bun", "-e", "process.exit(2)"], oneshot: true },
+      {
+        name: "worker",
+        command: ["bun", "-e", "setInterval(() => {}, 1000)"],
+        depends_on: ["broken"],
+      },
+    ]);
+
+    try {
+      await manager.startAll();
+      await manager.restartAll();
+      const counts = Object.fromEntries(
+        manager.getViews().map((view) => [view.name, view.restartCount]),
+      );
+      expect(counts).toEqual({ db: 1, broken: 0, worker: 0 });
+    } finally {
+      await manager.stopAll();
+    }
+  });
+
+  test("reloads configs by adding, updating and removing services", async () => {
+    const keepAlive = ["bun", "-e", "setInterval(() => {}, 1000)"];
+    const manager = new ServiceManager([
+      { name: "keep", command: keepAlive },
+      { name: "change", command: keepAlive },
+      { name: "drop", command: keepAlive },
+    ]);
+
+    try {
+      await manager.startAll();
+      const pidOf = (name: string) =>
+        manager.getServicePids().find((entry) => entry.name === name)?.pid;
+      const keepPid = pidOf("keep");
+
+      await expect(
+        manager.reload([{ name: "keep", command: keepAlive, depends_on: ["missing"] }]),
+      ).rejects.toThrow(ServiceManagerError);
+      expect(manager.getConfigs().map((config) => config.name)).toEqual(["keep", "change", "drop"]);
+
+      const summary = await manager.reload([
+        { name: "keep", command: keepAlive },
+        { name: "change", command: ["bun", "-e", "setInterval(() => {}, 500)"] },
+        { name: "add", command: keepAlive },
+      ]);
+
+      expect(summary).toEqual({ added: ["add"], updated: ["change"], removed: ["drop"] });
+      expect(manager.getConfigs().map((config) => config.name)).toEqual(["keep", "change", "add"]);
+      expect(pidOf("keep")).toBe(keepPid);
+      expect(pidOf("add")).toBeGreaterThan(0);
+    } finally {
+      await manager.stopAll();
+    }
+  });
+
+  test("keeps the selected service when a reload removes one above it", async () => {
+    const manager = new ServiceManager([makeConfig("a"), makeConfig("b"), makeConfig("c")]);
+    manager.setSelectedIndex(2);
+
+    await manager.reload([makeConfig("b"), makeConfig("c")]);
+    expect(manager.getSelectedView()?.name).toBe("c");
+
+    await manager.reload([makeConfig("b")]);
+    expect(manager.getSelectedView()?.name).toBe("b");
+    await manager.stopAll();
+  });
+
+  test("sends the reload signal to the selected service", async () => {
+    const reloadScript = [
+      "process.on('SIGHUP', () => console.log('reloaded'));",
+      "setInterval(() => {}, 1000);",
+    ].join(" ");
+
+    const manager = new ServiceManager([
+      { name: "api", command: ["bun", "-e", reloadScript], reload_signal: "SIGHUP" },
+      makeConfig("plain"),
+    ]);
+
+    try {
+      await manager.startAll();
+      const running = await waitFor(() => manager.getSelectedView()?.state === "RUNNING", 5000);
+      expect(running).toBe(true);
+
+      manager.reloadSelected();
+      const reloaded = await waitFor(() =>
+        (manager.getSelectedView()?.log.all() ?? []).some((entry) => entry.line === "reloaded"),
+      );
+      expect(reloaded).toBe(true);
+      expect(manager.getSelectedView()?.state).toBe("RUNNING");
+
+      manager.setSelectedIndex(1);
+      expect(() => manager.reloadSelected()).toThrow(ServiceManagerError);
+    } finally {
+      await manager.stopAll();
+    }
+  });
+
+  test("signals a service that is still settling in STARTING", async () => {
+    const script = [
+      "process.on('SIGUSR1', () => console.log('got usr1'));",
+      "console.log('ready');",
+      "setInterval(() => {}, 1000);",
+    ].join(" ");
+    const manager = new ServiceManager([
+      { name: "api", command: ["bun", "-e", script], reload_signal: "SIGUSR1" },
+    ]);
+    const lines = () => (manager.getSelectedView()?.log.all() ?? []).map((entry) => entry.line);
+
+    try {
+      const starting = manager.startSelected();
+      expect(await waitFor(() => lines().includes("ready"))).toBe(true);
+      expect(manager.getSelectedView()?.state).toBe("STARTING");
+
+      manager.reloadSelected();
+      manager.signalSelected("SIGUSR1");
+      const received = await waitFor(
+        () => lines().filter((line) => line === "got usr1").length === 2,
+      );
+      expect(received).toBe(true);
+      await starting;
+    } finally {
+      await manager.stopAll();
+    }
+  });
+
   test("force-stops stubborn services that ignore SIGINT and SIGTERM", async () => {
     const stubbornScript = [
       "process.on('SIGINT', () => {});",
@@ -200,4 +490,187 @@ describe("ServiceManager", () => {
     const afterStopRestartCount = manager.getSelectedView()?.restartCount ?? 0;
     expect(afterStopRestartCount).toBe(restartCount);
   });
+
+  test("halts restarts once a crash loop is detected", async () => {
+    const manager = new ServiceManager([
+      {
+        name: "looping",
+        command: ["bun", "-e", "process.exit(1)"],
+        restart_policy: "always",
+        restart_limit: 1,
+        restart_window: "60s",
+      },
+    ]);
+
+    await manager.startAll();
+    const halted = await waitFor(() => {
+      const view = manager.getSelectedView();
+      return view?.state === "FAILED" && view.restartInMs === null && view.restartCount === 1;
+    }, 4000);
+
+    expect(halted).toBe(true);
+    const lines = manager.getSelectedView()?.log.all().map((entry) => entry.line) ?? [];
+    expect(lines.some((line) => line.startsWith("crash loop detected"))).toBe(true);
+
+    await delay(500);
+    expect(manager.getSelectedView()?.restartCount).toBe(1);
+    await manager.stopAll();
+  });
+
+  test("flags services that go over their memory budget", async () => {
+    const keepAlive = ["bun", "-e", "setInterval(() => {}, 1000)"];
+    const manager = new ServiceManager([
+      { name: "heavy", command: keepAlive, mem_alert: 1 },
+      { name: "light", command: keepAlive, mem_alert: 1024 * 1024 },
+    ]);
+
+    try {
+      await manager.startAll();
+      manager.startResourceSampling(100);
+      const sampled = await waitFor(() =>
+        manager.getViews().every((view) => view.memBytes !== null && view.memBytes > 0),
+      );
+      expect(sampled).toBe(true);
+
+      const [heavy, light] = manager.getViews();
+      expect(heavy?.alerts).toEqual(["mem"]);
+      expect(light?.alerts).toEqual([]);
+      const lines = heavy?.log.all().map((entry) => entry.line) ?? [];
+      expect(lines).toContain("resource alert: memory over 1MiB");
+    } finally {
+      manager.stopResourceSampling();
+      await manager.stopAll();
+    }
+  });
+
+  test("records the desired state of started and stopped services", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-desired-"));
+    const desiredStatePath = join(dir, "state.json");
+    const keepAlive = ["bun", "-e", "setInterval(() => {}, 1000)"];
+    const manager = new ServiceManager(
+      [
+        { name: "db", command: keepAlive },
+        { name: "api", command: keepAlive, depends_on: ["db"] },
+        { name: "web", command: keepAlive },
+      ],
+      { desiredStatePath },
+    );
+
+    try {
+      await manager.startAll();
+      expect(await loadDesiredStates(desiredStatePath)).toEqual({
+        db: "running",
+        api: "running",
+        web: "running",
+      });
+
+      await manager.stopSelected();
+      expect(await loadDesiredStates(desiredStatePath)).toEqual({
+        db: "stopped",
+        api: "stopped",
+        web: "running",
+      });
+
+      manager.setSelectedIndex(1);
+      await manager.startSelected();
+      await manager.stopAll({ keepDesiredState: true });
+      expect(await loadDesiredStates(desiredStatePath)).toEqual({
+        db: "running",
+        api: "running",
+        web: "running",
+      });
+
+      await manager.stopAll();
+      expect(Object.values(await loadDesiredStates(desiredStatePath))).toEqual([
+        "stopped",
+        "stopped",
+        "stopped",
+      ]);
+    } finally {
+      await manager.stopAll({ keepDesiredState: true });
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("records only services that came up and prunes ones no longer in the manifest", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-desired-"));
+    const desiredStatePath = join(dir, "state.json");
+    await saveDesiredStates(desiredStatePath, { gone: "running", web: "stopped" });
+    const manager = new ServiceManager(
+      [
+        { name: "broken", command: ["bun", "-e", "process.exit(2)"], oneshot: true },
+        {
+          name: "worker",
+          command: ["bun", "-e", "setInterval(() => {}, 1000)"],
+          depends_on: ["broken"],
+        },
+        { name: "web", command: ["bun", "-e", "setInterval(() => {}, 1000)"] },
+      ],
+      { desiredStatePath },
+    );
+
+    try {
+      manager.setSelectedIndex(1);
+      await manager.startSelected();
+      expect(manager.getSelectedView()?.state).toBe("STOPPED");
+      expect(await loadDesiredStates(desiredStatePath)).toEqual({ web: "stopped" });
+    } finally {
+      await manager.stopAll({ keepDesiredState: true });
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("warns instead of failing the action when the state file cannot be written", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-desired-"));
+    await Bun.write(join(dir, "blocker"), "");
+    const warnings: string[] = [];
+    const manager = new ServiceManager(
+      [{ name: "web", command: ["bun", "-e", "setInterval(() => {}, 1000)"] }],
+      {
+        desiredStatePath: join(dir, "blocker", "state.json"),
+        logger: (message) => warnings.push(message),
+      },
+    );
+
+    try {
+      await manager.startSelected();
+      expect(manager.getSelectedView()?.state).toBe("RUNNING");
+      expect(warnings).toHaveLength(1);
+      expect(warnings[0]).toStartWith("Failed to save desired service state:");
+    } finally {
+      await manager.stopAll({ keepDesiredState: true });
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("leaves services recorded as stopped down on a resumed start", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-desired-"));
+    const desiredStatePath = join(dir, "state.json");
+    const keepAlive = ["bun", "-e", "setInterval(() => {}, 1000)"];
+    await saveDesiredStates(desiredStatePath, { api: "stopped", db: "running" });
+    const manager = new ServiceManager(
+      [
+        { name: "db", command: keepAlive },
+        { name: "api", command: keepAlive, depends_on: ["db"] },
+        { name: "web", command: keepAlive },
+      ],
+      { desiredStatePath },
+    );
+
+    try {
+      await manager.startAll({ resume: true });
+      expect(manager.getViews().map((view) => [view.name, view.state])).toEqual([
+        ["db", "RUNNING"],
+        ["api", "STOPPED"],
+        ["web", "RUNNING"],
+      ]);
+      expect(await loadDesiredStates(desiredStatePath)).toEqual({
+        api: "stopped",
+        db: "running",
+      });
+    } finally {
+      await manager.stopAll({ keepDesiredState: true });
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
 });
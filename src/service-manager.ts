@@ -1,4 +1,12 @@
-import { LogBuffer } from "./log-buffer";
+import {
+  type DesiredState,
+  type DesiredStates,
+  loadDesiredStates,
+  saveDesiredStates,
+} from "./desired-state";
+import type { InterpolationContext } from "./interpolate";
+import { DEFAULT_LOG_CAPACITY, LogBuffer } from "./log-buffer";
+import { ProcessSampler } from "./process-stats";
 import { type ServiceEvent, ServiceProcess } from "./service";
 import {
   ServiceGraphError,
@@ -8,6 +16,7 @@ import {
   getTopologicalServiceOrder,
   validateServiceGraph,
 } from "./service-graph";
+import { getErrorMessage, parseDuration } from "./shared";
 import type { ServiceConfig, ServicePid, ServiceState } from "./types";
 
 export interface ServiceView {
@@ -16,18 +25,94 @@ export interface ServiceView {
   lastExitCode: number | null;
   restartCount: number;
   restartInMs: number | null;
+  cpuPercent: number | null;
+  memBytes: number | null;
+  // The most recent samples, oldest first; kept across restarts so trends stay visible.
+  cpuHistory: number[];
+  memHistory: number[];
+  // Budgets from cpu_alert / mem_alert that the latest sample exceeds.
+  alerts: ResourceAlert[];
+  pids: number[];
+  ports: number[];
+  // The last run exited 0 on its own rather than being stopped.
+  completed: boolean;
   log: LogBuffer;
   config: ServiceConfig;
 }
 
+export type ResourceAlert = "cpu" | "mem";
+
 export type UpdateCallback = () => void;
 
-const LOG_CAPACITY = 2000;
+export interface ServiceReloadSummary {
+  added: string[];
+  updated: string[];
+  removed: string[];
+}
+
+export interface ServiceManagerOptions {
+  interpolation?: InterpolationContext;
+  procRoot?: string;
+  logLines?: number;
+  resourceHistory?: number;
+  // Where start and stop actions record each service's desired state across sessions.
+  desiredStatePath?: string;
+  logger?: (message: string) => void;
+}
+
 const WAIT_INTERVAL_MS = 50;
 const SERVICE_STOP_TIMEOUT_MS = 2000;
 const RESTART_BASE_DELAY_MS = 250;
 const RESTART_MAX_DELAY_MS = 5000;
 const RUN_STABLE_RESET_MS = 5000;
+const DEFAULT_RESTART_LIMIT = 5;
+const DEFAULT_RESTART_WINDOW_MS = 60_000;
+const DEFAULT_ONESHOT_TIMEOUT_MS = 5 * 60_000;
+const RESOURCE_SAMPLE_INTERVAL_MS = 2000;
+const DEFAULT_RESOURCE_HISTORY = 16;
+
+const createView = (config: ServiceConfig, logLines: number): ServiceView => ({
+  name: config.name,
+  state: "STOPPED",
+  lastExitCode: null,
+  restartCount: 0,
+  restartInMs: null,
+  cpuPercent: null,
+  memBytes: null,
+  cpuHistory: [],
+  memHistory: [],
+  alerts: [],
+  pids: [],
+  ports: [],
+  completed: false,
+  log: new LogBuffer(logLines),
+  config,
+});
+
+const MIB = 1024 ** 2;
+
+const getResourceAlerts = (
+  config: ServiceConfig,
+  cpuPercent: number | null,
+  memBytes: number | null,
+): ResourceAlert[] => {
+  const alerts: ResourceAlert[] = [];
+  if (config.cpu_alert !== undefined && cpuPercent !== null && cpuPercent > config.cpu_alert) {
+    alerts.push("cpu");
+  }
+  if (config.mem_alert !== undefined && memBytes !== null && memBytes > config.mem_alert * MIB) {
+    alerts.push("mem");
+  }
+  return alerts;
+};
+
+const describeAlert = (config: ServiceConfig, alert: ResourceAlert): string =>
+  alert === "cpu" ? `cpu over ${config.cpu_alert}%` : `memory over ${config.mem_alert}MiB`;
+
+const pushSample = (history: number[], value: number, limit: number): void => {
+  history.push(value);
+  if (history.length > limit) history.splice(0, history.length - limit);
+};
 
 export class ServiceManagerError extends Error {
   constructor(message: string) {
@@ -44,24 +129,34 @@ export class ServiceManager {
   private readonly restartTimers: Map<ServiceProcess, ReturnType<typeof setTimeout>> = new Map();
   private readonly restartAttempts: Map<ServiceProcess, number> = new Map();
   private readonly restartDeadlines: Map<ServiceProcess, number> = new Map();
+  private readonly restartFailures: Map<ServiceProcess, number[]> = new Map();
   private readonly runStableTimers: Map<ServiceProcess, ReturnType<typeof setTimeout>> = new Map();
   private restartTicker: ReturnType<typeof setInterval> | null = null;
+  private resourceTimer: ReturnType<typeof setInterval> | null = null;
+  private sampling = false;
+  private readonly sampler: ProcessSampler;
   private readonly updateCallbacks: Set<UpdateCallback> = new Set();
   private readonly processCallbacks: Set<UpdateCallback> = new Set();
   private selectedIndex = 0;
-
-  constructor(configs: ServiceConfig[]) {
+  private readonly actionCounts: Map<string, number> = new Map();
+  private interpolation: InterpolationContext | undefined;
+  private readonly logLines: number;
+  private readonly resourceHistory: number;
+  private readonly desiredStatePath: string | undefined;
+  private readonly logger: ((message: string) => void) | undefined;
+  private desiredStates: Promise<DesiredStates> | null = null;
+  private desiredStateWrite: Promise<void> = Promise.resolve();
+
+  constructor(configs: ServiceConfig[], options: ServiceManagerOptions = {}) {
     this.assertValidConfigGraph(configs);
-    this.services = configs.map((config) => new ServiceProcess(config));
-    this.views = this.services.map((service) => ({
-      name: service.config.name,
-      state: "STOPPED",
-      lastExitCode: null,
-      restartCount: 0,
-      restartInMs: null,
-      log: new LogBuffer(LOG_CAPACITY),
-      config: service.config,
-    }));
+    this.interpolation = options.interpolation;
+    this.logLines = options.logLines ?? DEFAULT_LOG_CAPACITY;
+    this.resourceHistory = options.resourceHistory ?? DEFAULT_RESOURCE_HISTORY;
+    this.desiredStatePath = options.desiredStatePath;
+    this.logger = options.logger;
+    this.sampler = new ProcessSampler({ root: options.procRoot });
+    this.services = configs.map((config) => new ServiceProcess(config, this.interpolation));
+    this.views = this.services.map((service) => createView(service.config, this.logLines));
     this.unsubscribers = this.services.map((service) => this.subscribeService(service));
   }
 
@@ -118,23 +213,74 @@ export class ServiceManager {
     return entries;
   }
 
-  async startAll(options: { shouldCancel?: () => boolean } = {}): Promise<void> {
+  getActionCounts(): Record<string, number> {
+    return Object.fromEntries(this.actionCounts);
+  }
+
+  private countAction(action: string): void {
+    this.actionCounts.set(action, (this.actionCounts.get(action) ?? 0) + 1);
+  }
+
+  // With resume, services last recorded as stopped stay stopped and the record is left as is;
+  // that is how a launch picks up the previous session.
+  async startAll(options: { shouldCancel?: () => boolean; resume?: boolean } = {}): Promise<void> {
+    this.countAction("start_all");
+    if (!options.resume) {
+      const started = await this.startEvery(options);
+      await this.recordDesiredState([...started], "running");
+      return;
+    }
+
+    const states = await this.getDesiredStates();
+    const skip = new Set(Object.keys(states).filter((name) => states[name] === "stopped"));
+    await this.startEvery({ ...options, skip });
+  }
+
+  // keepDesiredState is for quitting: services stop now but start again on the next launch.
+  async stopAll(options: { keepDesiredState?: boolean } = {}): Promise<void> {
+    this.countAction("stop_all");
+    await this.stopEvery();
+    if (!options.keepDesiredState) {
+      const stopped = this.getStoppedNames(this.getTopologicalOrderNames());
+      await this.recordDesiredState(stopped, "stopped");
+    }
+  }
+
+  async restartAll(): Promise<void> {
+    this.countAction("restart_all");
+    await this.stopEvery();
+    const started = await this.startEvery();
+
+    for (const view of this.views) {
+      if (started.has(view.name)) view.restartCount += 1;
+    }
+    this.notify();
+  }
+
+  // Resolves to the names that came up: still running, or oneshots that completed.
+  private async startEvery(
+    options: { shouldCancel?: () => boolean; skip?: Set<string> } = {},
+  ): Promise<Set<string>> {
     const layers = this.getTopologicalLayers();
+    const started = new Set<string>();
 
     for (const layer of layers) {
-      if (options.shouldCancel?.()) return;
+      if (options.shouldCancel?.()) break;
 
       await Promise.all(
         layer.map(async (name) => {
           const service = this.getServiceByName(name);
-          if (!service) return;
+          if (!service || options.skip?.has(name) || this.isBlockedByOneshot(name)) return;
           await this.startService(service);
+          if (this.isUp(service)) started.add(name);
         }),
       );
     }
+
+    return started;
   }
 
-  async stopAll(): Promise<void> {
+  private async stopEvery(): Promise<void> {
     await this.forEachResolvedService(
       this.getTopologicalOrderNames().reverse(),
       async (service) => {
@@ -156,30 +302,36 @@ export class ServiceManager {
   async startSelected(): Promise<void> {
     const service = this.services[this.selectedIndex];
     if (!service) return;
+    this.countAction("start");
 
+    const started: string[] = [];
     await this.forEachResolvedService(
       this.getStartOrderForService(service.config.name),
       async (next) => {
+        if (this.isBlockedByOneshot(next.config.name)) return;
         await this.startService(next);
+        if (this.isUp(next)) started.push(next.config.name);
       },
     );
+    await this.recordDesiredState(started, "running");
   }
 
   async stopSelected(): Promise<void> {
     const service = this.services[this.selectedIndex];
     if (!service) return;
+    this.countAction("stop");
 
-    await this.forEachResolvedService(
-      this.getStopOrderForService(service.config.name),
-      async (next) => {
-        await this.stopService(next);
-      },
-    );
+    const names = this.getStopOrderForService(service.config.name);
+    await this.forEachResolvedService(names, async (next) => {
+      await this.stopService(next);
+    });
+    await this.recordDesiredState(this.getStoppedNames(names), "stopped");
   }
 
   async killSelected(): Promise<void> {
     const service = this.services[this.selectedIndex];
     if (!service) return;
+    this.countAction("kill");
 
     await this.forEachResolvedService(
       this.getStopOrderForService(service.config.name),
@@ -190,25 +342,65 @@ export class ServiceManager {
     );
   }
 
+  // Asks the selected service to reload its config in place via its reload_signal.
+  reloadSelected(): void {
+    const service = this.services[this.selectedIndex];
+    if (!service) return;
+
+    const signal = service.config.reload_signal;
+    if (!signal) {
+      throw new ServiceManagerError(`${service.config.name} has no reload_signal configured`);
+    }
+    this.countAction("reload");
+    this.deliverSignal(signal, `reload requested (${signal})`);
+  }
+
+  // Signals the selected service without marking it as stopping, so restart policies still apply.
+  signalSelected(signal: NodeJS.Signals): void {
+    this.countAction("signal");
+    this.deliverSignal(signal, `sent ${signal}`);
+  }
+
+  private deliverSignal(signal: NodeJS.Signals, note: string): void {
+    const service = this.services[this.selectedIndex];
+    const view = this.views[this.selectedIndex];
+    if (!service || !view) return;
+
+    if (!service.sendSignal(signal)) {
+      throw new ServiceManagerError(`${service.config.name} is not running`);
+    }
+
+    view.log.add({
+      timestamp: new Date().toISOString(),
+      line: note,
+      stream: "stdout",
+    });
+    this.notify();
+  }
+
   async restartSelected(): Promise<void> {
     const service = this.services[this.selectedIndex];
     if (!service) return;
+    this.countAction("restart");
     const view = this.views[this.selectedIndex];
     await this.stopService(service);
 
     await this.forEachResolvedService(
       this.getStartOrderForService(service.config.name),
       async (next) => {
+        if (this.isBlockedByOneshot(next.config.name)) return;
         await this.startService(next);
       },
     );
 
-    if (view) {
+    if (view && (service.isRunning() || view.completed)) {
       view.restartCount += 1;
       this.notify();
     }
   }
 
+  // Adding and editing only apply the config; callers save it and then call startNamed, whose
+  // oneshot dependencies can take minutes to finish.
   async addService(config: ServiceConfig): Promise<void> {
     if (this.hasServiceName(config.name)) {
       throw new ServiceManagerError(`Service name already exists: ${config.name}`);
@@ -216,46 +408,22 @@ export class ServiceManager {
 
     this.assertValidConfigGraph([...this.getConfigs(), config]);
 
-    const process = new ServiceProcess(config);
-    this.services.push(process);
-    this.views.push({
-      name: config.name,
-      state: "STOPPED",
-      lastExitCode: null,
-      restartCount: 0,
-      restartInMs: null,
-      log: new LogBuffer(LOG_CAPACITY),
-      config,
-    });
-    this.unsubscribers.push(this.subscribeService(process));
+    this.appendService(config);
+    this.notify();
+  }
 
-    await this.forEachResolvedService(this.getStartOrderForService(config.name), async (next) => {
+  // Starts a service by name along with its dependencies, as startSelected does.
+  async startNamed(name: string): Promise<void> {
+    await this.forEachResolvedService(this.getStartOrderForService(name), async (next) => {
+      if (this.isBlockedByOneshot(next.config.name)) return;
       await this.startService(next);
     });
-
     this.notify();
   }
 
   async removeSelected(): Promise<boolean> {
     if (this.services.length === 0) return false;
-    const index = this.selectedIndex;
-    const service = this.services[index];
-    if (!service) return false;
-
-    await this.stopService(service);
-    this.clearServiceRuntimeState(service);
-
-    this.unsubscribers[index]?.();
-    this.unsubscribers.splice(index, 1);
-    this.services.splice(index, 1);
-    this.views.splice(index, 1);
-
-    if (this.selectedIndex >= this.views.length && this.views.length > 0) {
-      this.selectedIndex = this.views.length - 1;
-    }
-    if (this.views.length === 0) {
-      this.selectedIndex = 0;
-    }
+    if (!(await this.removeServiceAt(this.selectedIndex))) return false;
 
     this.notify();
     return true;
@@ -272,30 +440,68 @@ export class ServiceManager {
     const nextConfigs = this.getConfigs().map((entry, i) => (i === index ? config : entry));
     this.assertValidConfigGraph(nextConfigs);
 
-    await this.stopService(oldService);
-    this.clearServiceRuntimeState(oldService);
-    this.unsubscribers[index]?.();
+    await this.replaceServiceAt(index, config);
+    this.notify();
+  }
 
-    const newProcess = new ServiceProcess(config);
-    this.services[index] = newProcess;
+  // Applies a freshly loaded manifest: services are matched by name, removed ones are stopped,
+  // and added or changed ones are (re)started. Invalid graphs throw before anything is touched.
+  async reload(
+    configs: ServiceConfig[],
+    interpolation: InterpolationContext | undefined = this.interpolation,
+  ): Promise<ServiceReloadSummary> {
+    this.assertValidConfigGraph(configs);
 
-    const view = this.views[index];
-    if (view) {
-      view.name = config.name;
-      view.config = config;
-      view.state = "STOPPED";
-      view.lastExitCode = null;
-      view.restartInMs = null;
-      view.log.clear();
+    const contextChanged = JSON.stringify(interpolation) !== JSON.stringify(this.interpolation);
+    this.interpolation = interpolation;
+
+    const summary: ServiceReloadSummary = { added: [], updated: [], removed: [] };
+    const nextNames = new Set(configs.map((config) => config.name));
+    for (const name of this.views.map((view) => view.name)) {
+      if (nextNames.has(name)) continue;
+      await this.removeServiceAt(this.views.findIndex((view) => view.name === name));
+      summary.removed.push(name);
     }
 
-    this.unsubscribers[index] = this.subscribeService(newProcess);
+    for (const config of configs) {
+      const index = this.views.findIndex((view) => view.name === config.name);
+      if (index === -1) {
+        this.appendService(config);
+        summary.added.push(config.name);
+        continue;
+      }
 
-    await this.forEachResolvedService(this.getStartOrderForService(config.name), async (next) => {
-      await this.startService(next);
-    });
+      const unchanged = JSON.stringify(this.views[index]?.config) === JSON.stringify(config);
+      if (unchanged && !contextChanged) continue;
+      await this.replaceServiceAt(index, config);
+      summary.updated.push(config.name);
+    }
+
+    const changed = new Set([...summary.added, ...summary.updated]);
+    for (const name of this.getTopologicalOrderNames()) {
+      if (!changed.has(name)) continue;
+      await this.forEachResolvedService(this.getStartOrderForService(name), async (next) => {
+        if (this.isBlockedByOneshot(next.config.name)) return;
+        await this.startService(next);
+      });
+    }
 
     this.notify();
+    return summary;
+  }
+
+  startResourceSampling(intervalMs = RESOURCE_SAMPLE_INTERVAL_MS): void {
+    this.stopResourceSampling();
+    void this.sampleResources();
+    this.resourceTimer = setInterval(() => {
+      void this.sampleResources();
+    }, intervalMs);
+  }
+
+  stopResourceSampling(): void {
+    if (!this.resourceTimer) return;
+    clearInterval(this.resourceTimer);
+    this.resourceTimer = null;
   }
 
   async waitForExit(timeoutMs: number): Promise<boolean> {
@@ -314,6 +520,7 @@ export class ServiceManager {
 
     if (event.type === "state") {
       view.state = event.state;
+      if (event.state === "STARTING") view.completed = false;
       if (event.state === "RUNNING") {
         view.restartInMs = null;
         this.scheduleStableRunReset(service);
@@ -324,6 +531,7 @@ export class ServiceManager {
     } else if (event.type === "exit") {
       this.clearRunStableTimer(service);
       view.lastExitCode = event.code;
+      view.completed = event.code === 0 && !this.autoRestartSuppressed.has(service);
       this.notifyProcessChange();
       this.maybeScheduleRestart(service, view, event.code);
     }
@@ -331,6 +539,70 @@ export class ServiceManager {
     this.notify();
   }
 
+  private async sampleResources(): Promise<void> {
+    if (this.sampling) return;
+    this.sampling = true;
+
+    try {
+      const leaders = this.services
+        .map((service) => service.getPid())
+        .filter((pid): pid is number => pid !== null);
+      const usage = await this.sampler.sample(leaders);
+
+      let changed = false;
+      for (const service of this.services) {
+        const view = this.getViewByService(service);
+        if (!view) continue;
+
+        const pid = service.getPid();
+        const sample = pid !== null ? usage.get(pid) : undefined;
+        const cpuPercent = sample?.cpuPercent ?? null;
+        const memBytes = sample?.memBytes ?? null;
+        const pids = sample?.pids ?? [];
+        const ports = sample?.ports ?? [];
+        if (sample) {
+          if (sample.cpuPercent !== null) {
+            pushSample(view.cpuHistory, sample.cpuPercent, this.resourceHistory);
+          }
+          pushSample(view.memHistory, sample.memBytes, this.resourceHistory);
+          changed = true;
+        }
+        const alerts = getResourceAlerts(view.config, cpuPercent, memBytes);
+        const raised = alerts.filter((alert) => !view.alerts.includes(alert));
+        if (raised.length > 0) {
+          const described = raised.map((alert) => describeAlert(view.config, alert));
+          view.log.add({
+            timestamp: new Date().toISOString(),
+            line: `resource alert: ${described.join(", ")}`,
+            stream: "stderr",
+          });
+        }
+        if (alerts.join(",") !== view.alerts.join(",")) {
+          view.alerts = alerts;
+          changed = true;
+        }
+        if (
+          view.cpuPercent === cpuPercent &&
+          view.memBytes === memBytes &&
+          view.pids.join(",") === pids.join(",") &&
+          view.ports.join(",") === ports.join(",")
+        ) {
+          continue;
+        }
+
+        view.cpuPercent = cpuPercent;
+        view.memBytes = memBytes;
+        view.pids = pids;
+        view.ports = ports;
+        changed = true;
+      }
+
+      if (changed) this.notify();
+    } finally {
+      this.sampling = false;
+    }
+  }
+
   private notify() {
     for (const callback of this.updateCallbacks) {
       callback();
@@ -431,8 +703,158 @@ export class ServiceManager {
 
     if (options.resetAttempts) {
       this.restartAttempts.set(service, 0);
+      this.restartFailures.delete(service);
     }
     await service.start();
+    await this.waitForStartup(service);
+    await this.waitForStartDelay(service);
+    // Oneshot services are prerequisites, so their start lasts until the process exits.
+    if (service.config.oneshot) {
+      await this.waitForOneshot(service);
+    }
+  }
+
+  // A oneshot that outlives its timeout is stopped and failed, which keeps dependents stopped.
+  private async waitForOneshot(service: ServiceProcess): Promise<void> {
+    const { timeout } = service.config;
+    const timeoutMs =
+      timeout === undefined
+        ? DEFAULT_ONESHOT_TIMEOUT_MS
+        : (parseDuration(timeout) ?? DEFAULT_ONESHOT_TIMEOUT_MS);
+    if (await this.waitForServiceExit(service, timeoutMs)) return;
+
+    await this.stopService(service);
+    service.markFailed(`oneshot did not finish within ${timeoutMs}ms, stopped`);
+  }
+
+  // Dependents of a oneshot that did not complete stay stopped; its log shows why.
+  private isBlockedByOneshot(name: string): boolean {
+    const closure = this.runGraphOperation(() => getDependencyClosure(this.getConfigs(), name));
+    closure.delete(name);
+    return this.views.some(
+      (view) => closure.has(view.name) && view.config.oneshot === true && !view.completed,
+    );
+  }
+
+  private getDesiredStates(): Promise<DesiredStates> {
+    if (!this.desiredStatePath) return Promise.resolve({});
+    this.desiredStates ??= loadDesiredStates(this.desiredStatePath);
+    return this.desiredStates;
+  }
+
+  // Still running, or a oneshot that ran to completion.
+  private isUp(service: ServiceProcess): boolean {
+    return service.isRunning() || this.getViewByService(service)?.completed === true;
+  }
+
+  private getStoppedNames(names: string[]): string[] {
+    return names.filter((name) => !this.getServiceByName(name)?.isRunning());
+  }
+
+  // Called once the outcome is known, so only services that really came up or went down are
+  // recorded. Services no longer in the manifest are pruned, and writes are chained so the file
+  // ends with the latest record. A failed write is only a warning: the action already happened.
+  private async recordDesiredState(names: string[], state: DesiredState): Promise<void> {
+    const path = this.desiredStatePath;
+    if (!path) return;
+
+    const states = await this.getDesiredStates();
+    for (const name of names) {
+      states[name] = state;
+    }
+    const known = new Set(this.getConfigs().map((config) => config.name));
+    for (const name of Object.keys(states)) {
+      if (!known.has(name)) delete states[name];
+    }
+
+    const snapshot = { ...states };
+    this.desiredStateWrite = this.desiredStateWrite.then(() =>
+      saveDesiredStates(path, snapshot).catch((error) => {
+        this.logger?.(`Failed to save desired service state: ${getErrorMessage(error)}`);
+      }),
+    );
+    await this.desiredStateWrite;
+  }
+
+  // A start is only done once the service has settled into RUNNING or exited, so dependents
+  // never launch against a process that is still in its STARTING window.
+  private async waitForStartup(service: ServiceProcess): Promise<void> {
+    while (service.getState() === "STARTING") {
+      await new Promise((resolve) => setTimeout(resolve, WAIT_INTERVAL_MS));
+    }
+  }
+
+  // start_delay holds back dependents after a spawn; it ends early if the process exits.
+  private async waitForStartDelay(service: ServiceProcess): Promise<void> {
+    const { start_delay: startDelay } = service.config;
+    const delayMs = startDelay === undefined ? 0 : (parseDuration(startDelay) ?? 0);
+    const deadline = Date.now() + delayMs;
+    while (Date.now() < deadline && service.isRunning()) {
+      await new Promise((resolve) => setTimeout(resolve, WAIT_INTERVAL_MS));
+    }
+  }
+
+  private appendService(config: ServiceConfig): void {
+    const service = new ServiceProcess(config, this.interpolation);
+    this.services.push(service);
+    this.views.push(createView(config, this.logLines));
+    this.unsubscribers.push(this.subscribeService(service));
+  }
+
+  private async removeServiceAt(index: number): Promise<boolean> {
+    const service = this.services[index];
+    if (!service) return false;
+
+    await this.stopService(service);
+    this.clearServiceRuntimeState(service);
+
+    this.unsubscribers[index]?.();
+    this.unsubscribers.splice(index, 1);
+    this.services.splice(index, 1);
+    this.views.splice(index, 1);
+
+    // Keep the cursor on the same service when something above it disappears.
+    if (index < this.selectedIndex) {
+      this.selectedIndex -= 1;
+    }
+    if (this.selectedIndex >= this.views.length && this.views.length > 0) {
+      this.selectedIndex = this.views.length - 1;
+    }
+    if (this.views.length === 0) {
+      this.selectedIndex = 0;
+    }
+    return true;
+  }
+
+  private async replaceServiceAt(index: number, config: ServiceConfig): Promise<void> {
+    const oldService = this.services[index];
+    if (!oldService) return;
+
+    await this.stopService(oldService);
+    this.clearServiceRuntimeState(oldService);
+    this.unsubscribers[index]?.();
+
+    const newProcess = new ServiceProcess(config, this.interpolation);
+    this.services[index] = newProcess;
+
+    const view = this.views[index];
+    if (view) {
+      view.name = config.name;
+      view.config = config;
+      view.state = "STOPPED";
+      view.lastExitCode = null;
+      view.restartInMs = null;
+      view.cpuPercent = null;
+      view.memBytes = null;
+      view.cpuHistory = [];
+      view.memHistory = [];
+      view.alerts = [];
+      view.pids = [];
+      view.ports = [];
+      view.log.clear();
+    }
+
+    this.unsubscribers[index] = this.subscribeService(newProcess);
   }
 
   private hasServiceName(name: string, exceptIndex: number | null = null): boolean {
@@ -470,6 +892,8 @@ export class ServiceManager {
     }
   }
 
+  // A stable run only resets the backoff. Failures age out of the restart window on their own,
+  // so a service that crashes after a few healthy seconds still trips restart_limit.
   private scheduleStableRunReset(service: ServiceProcess): void {
     this.clearRunStableTimer(service);
 
@@ -495,6 +919,34 @@ export class ServiceManager {
     this.clearRestartDeadline(service);
     this.clearRunStableTimer(service);
     this.restartAttempts.delete(service);
+    this.restartFailures.delete(service);
+  }
+
+  // Records an unplanned exit and reports whether the service has exceeded its restart budget.
+  private recordRestartFailure(service: ServiceProcess, config: ServiceConfig): boolean {
+    const limit = config.restart_limit ?? DEFAULT_RESTART_LIMIT;
+    const windowMs =
+      config.restart_window === undefined
+        ? DEFAULT_RESTART_WINDOW_MS
+        : (parseDuration(config.restart_window) ?? DEFAULT_RESTART_WINDOW_MS);
+
+    const now = Date.now();
+    const failures = (this.restartFailures.get(service) ?? []).filter((at) => now - at < windowMs);
+    failures.push(now);
+    this.restartFailures.set(service, failures);
+    return failures.length > limit;
+  }
+
+  private haltCrashLoop(service: ServiceProcess): void {
+    const failures = this.restartFailures.get(service)?.length ?? 0;
+    this.restartFailures.delete(service);
+    this.restartAttempts.set(service, 0);
+    this.clearRestartTimer(service);
+    this.clearRestartDeadline(service);
+
+    service.markFailed(
+      `crash loop detected: ${failures} failures within the restart window, not restarting`,
+    );
   }
 
   private maybeScheduleRestart(
@@ -514,6 +966,11 @@ export class ServiceManager {
     if (policy === "never") return;
     if (policy === "on-failure" && exitCode === 0) return;
 
+    if (this.recordRestartFailure(service, view.config)) {
+      this.haltCrashLoop(service);
+      return;
+    }
+
     const attempt = (this.restartAttempts.get(service) ?? 0) + 1;
     this.restartAttempts.set(service, attempt);
 
@@ -589,7 +1046,11 @@ export class ServiceManager {
   private async stopService(service: ServiceProcess): Promise<void> {
     this.suppressAutoRestart(service);
     this.clearRunStableTimer(service);
-    if (!service.isRunning()) return;
+    if (!service.isRunning()) {
+      // A restart or start that has not spawned yet is cancelled rather than left to finish.
+      if (service.getState() === "STARTING") await service.stop();
+      return;
+    }
 
     await service.stop();
     const stopped = await this.waitForServiceExit(service, SERVICE_STOP_TIMEOUT_MS);
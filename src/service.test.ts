@@ -1,5 +1,6 @@
 import { afterEach, describe, expect, test } from "bun:test";
-import { setPathReaderForTests, resetPathCacheForTests } from "./service";
+import { ServiceProcess, setPathReaderForTests, resetPathCacheForTests } from "./service";
+import type { ServiceState } from "./types";
 import { ServiceManager } from "./service-manager";
 
 const waitFor = async (
@@ -48,3 +49,142 @@ describe("service PATH cache", () => {
     }
   });
 });
+
+describe("service startup", () => {
+  const collectStates = (service: ServiceProcess): ServiceState[] => {
+    const states: ServiceState[] = [];
+    service.subscribe((event) => {
+      if (event.type === "state") states.push(event.state);
+    });
+    return states;
+  };
+
+  test("stays STARTING until the settle period passes", async () => {
+    const service = new ServiceProcess({
+      name: "api",
+      command: ["bun", "-e", "setInterval(() => {}, 1000)"],
+    });
+    const states = collectStates(service);
+
+    try {
+      await service.start();
+      expect(service.getState()).toBe("STARTING");
+
+      const running = await waitFor(() => service.getState() === "RUNNING", 3000);
+      expect(running).toBe(true);
+      expect(states).toEqual(["STARTING", "RUNNING"]);
+    } finally {
+      await service.forceStop("SIGKILL");
+    }
+  });
+
+  test("goes straight to FAILED when the process dies while starting", async () => {
+    const service = new ServiceProcess({
+      name: "broken",
+      command: ["bun", "-e", "process.exit(3)"],
+    });
+    const states = collectStates(service);
+
+    await service.start();
+    const failed = await waitFor(() => service.getState() === "FAILED");
+
+    expect(failed).toBe(true);
+    expect(states).toEqual(["STARTING", "FAILED"]);
+  });
+
+  test("marks an exited service as failed through its own state", async () => {
+    const service = new ServiceProcess({
+      name: "looping",
+      command: ["bun", "-e", "process.exit(0)"],
+    });
+    const states = collectStates(service);
+
+    await service.start();
+    await waitFor(() => service.getState() === "STOPPED");
+    service.markFailed("crash loop detected");
+
+    expect(service.getState()).toBe("FAILED");
+    expect(states).toEqual(["STARTING", "STOPPED", "FAILED"]);
+  });
+
+  test("logs the resolved command and working directory before spawning", async () => {
+    const service = new ServiceProcess(
+      {
+        name: "api",
+        command: ["bun", "-e", "process.exit(0)", "${GREETING}"],
+        working_dir: process.cwd(),
+      },
+      { root: process.cwd(), vars: { GREETING: "hello" }, env: {} },
+    );
+    const lines: string[] = [];
+    service.subscribe((event) => {
+      if (event.type === "log") lines.push(event.entry.line);
+    });
+
+    await service.start();
+    await waitFor(() => service.getState() !== "STARTING");
+
+    expect(lines[0]).toBe(`$ bun -e process.exit(0) hello  (cwd: ${process.cwd()})`);
+  });
+
+  test("a stop during a pending start cancels the spawn", async () => {
+    const pathGate: { release?: (path: string) => void } = {};
+    setPathReaderForTests(
+      () =>
+        new Promise<string>((resolve) => {
+          pathGate.release = resolve;
+        }),
+    );
+    const service = new ServiceProcess({
+      name: "api",
+      command: ["bun", "-e", "setInterval(() => {}, 1000)"],
+    });
+
+    const starting = service.start();
+    await waitFor(() => pathGate.release !== undefined);
+    await service.stop();
+    pathGate.release?.(process.env.PATH ?? "");
+    await starting;
+
+    expect(service.isRunning()).toBe(false);
+    expect(service.getState()).toBe("STOPPED");
+  });
+
+  test("a stop and restart during a pending start spawn exactly one process", async () => {
+    const pathGate: { release?: (path: string) => void } = {};
+    setPathReaderForTests(
+      () =>
+        new Promise<string>((resolve) => {
+          pathGate.release = resolve;
+        }),
+    );
+    const marker = `stasium-pending-start-${process.pid}-${Date.now()}`;
+    const service = new ServiceProcess({
+      name: "api",
+      command: ["bun", "-e", `setInterval(() => {}, 1000) // ${marker}`],
+    });
+    const launches = (): number => {
+      const found = Bun.spawnSync(["pgrep", "-f", marker]).stdout.toString().trim();
+      return found.length === 0 ? 0 : found.split("\n").length;
+    };
+
+    try {
+      const first = service.start();
+      await waitFor(() => pathGate.release !== undefined);
+      const ignored = service.start();
+      await service.stop();
+      const second = service.start();
+      pathGate.release?.(process.env.PATH ?? "");
+      await Promise.all([first, ignored, second]);
+
+      expect(service.isRunning()).toBe(true);
+      expect(service.getState()).toBe("STARTING");
+      expect(await waitFor(() => launches() === 1)).toBe(true);
+      await Bun.sleep(200);
+      expect(launches()).toBe(1);
+    } finally {
+      await service.forceStop("SIGKILL");
+      await waitFor(() => !service.isRunning());
+    }
+  });
+});
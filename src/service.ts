@@ -1,5 +1,7 @@
 import { readLiveProcessInfo, resolveRuntimeWorkingDir } from "./process-info";
 import { normalizeCommand } from "./command";
+import { resolveServiceEnv } from "./env-file";
+import { type InterpolationContext, interpolateService } from "./interpolate";
 import { getErrorMessage } from "./shared";
 import type { CommandSpec, LogEntry, ServiceConfig, ServicePid, ServiceState } from "./types";
 
@@ -15,6 +17,8 @@ const timestamp = (): string => new Date().toISOString();
 const lineDecoder = new TextDecoder();
 // Full process-tree cleanup relies on Unix process groups. Windows falls back to the direct child.
 const SHOULD_DETACH_PROCESS_GROUP = process.platform !== "win32";
+// A freshly spawned service stays STARTING this long so instant crashes never show as RUNNING.
+const STARTUP_SETTLE_MS = 1000;
 
 const splitLines = (buffer: string): { lines: string[]; rest: string } => {
   const parts = buffer.split(/\r?\n/);
@@ -89,8 +93,34 @@ const buildSpawnEnv = async (
   return overrides ? { ...baseEnv, ...overrides } : baseEnv;
 };
 
+// Runs a one-off command with a service's working dir and env, attached to this terminal and
+// unsupervised. Resolves to the command's exit code.
+export const runInServiceEnv = async (
+  config: ServiceConfig,
+  argv: string[],
+  interpolation?: InterpolationContext,
+): Promise<number> => {
+  const service = interpolation ? interpolateService(config, interpolation).service : config;
+  const workingDir = resolveRuntimeWorkingDir(service.working_dir);
+  const resolved = await resolveServiceEnv(service.env_file, service.env, workingDir);
+  for (const warning of resolved.warnings) {
+    console.error(warning);
+  }
+
+  const proc = Bun.spawn({
+    cmd: argv,
+    cwd: workingDir,
+    env: await buildSpawnEnv(service.working_dir, resolved.env),
+    stdin: "inherit",
+    stdout: "inherit",
+    stderr: "inherit",
+  });
+  return await proc.exited;
+};
+
 export class ServiceProcess {
   readonly config: ServiceConfig;
+  private readonly runtimeConfig: ServiceConfig;
   private readonly detached = SHOULD_DETACH_PROCESS_GROUP;
   private readonly workingDir: string;
   private state: ServiceState = "STOPPED";
@@ -98,16 +128,20 @@ export class ServiceProcess {
   private subscribers: Set<ServiceSubscriber> = new Set();
   private lastExitCode: number | null = null;
   private lastSignal: string | null = null;
-  private stopRequested = false;
+  // Bumped by every start and stop; a start that resumes under a newer generation was
+  // superseded and must not spawn or touch state.
+  private startGeneration = 0;
   private command: string[] = [];
   private startedAt: string | null = null;
   private identityVerified = false;
   private stdoutRemainder = "";
   private stderrRemainder = "";
+  private settleTimer: ReturnType<typeof setTimeout> | null = null;
 
-  constructor(config: ServiceConfig) {
+  constructor(config: ServiceConfig, interpolation?: InterpolationContext) {
     this.config = config;
-    this.workingDir = resolveRuntimeWorkingDir(config.working_dir);
+    this.runtimeConfig = interpolation ? interpolateService(config, interpolation).service : config;
+    this.workingDir = resolveRuntimeWorkingDir(this.runtimeConfig.working_dir);
   }
 
   subscribe(handler: ServiceSubscriber): () => void {
@@ -149,8 +183,8 @@ export class ServiceProcess {
   }
 
   async start(): Promise<void> {
-    if (this.isRunning()) return;
-    this.stopRequested = false;
+    if (this.isRunning() || this.state === "STARTING") return;
+    const generation = ++this.startGeneration;
     this.command = [];
     this.startedAt = null;
     this.identityVerified = false;
@@ -158,7 +192,7 @@ export class ServiceProcess {
 
     let argv: string[];
     try {
-      argv = normalizeCommand(this.config.command as CommandSpec);
+      argv = normalizeCommand(this.runtimeConfig.command as CommandSpec);
       this.command = [...argv];
     } catch (error) {
       this.lastExitCode = 1;
@@ -171,17 +205,42 @@ export class ServiceProcess {
       return;
     }
 
+    // Record exactly what is launched and from where, so start failures can be diagnosed.
+    this.emit({
+      type: "log",
+      entry: {
+        timestamp: timestamp(),
+        line: `$ ${argv.join(" ")}  (cwd: ${this.workingDir})`,
+        stream: "stdout",
+      },
+    });
+
     try {
-      const env = await buildSpawnEnv(this.config.working_dir, this.config.env);
+      const resolved = await resolveServiceEnv(
+        this.runtimeConfig.env_file,
+        this.runtimeConfig.env,
+        this.workingDir,
+      );
+      if (generation !== this.startGeneration) return;
+      for (const warning of resolved.warnings) {
+        this.emit({
+          type: "log",
+          entry: { timestamp: timestamp(), line: warning, stream: "stderr" },
+        });
+      }
+      const env = await buildSpawnEnv(this.runtimeConfig.working_dir, resolved.env);
+      // A stop, or a stop and a new start, that arrived while the env was resolving wins.
+      if (generation !== this.startGeneration) return;
       this.process = Bun.spawn({
         cmd: argv,
-        cwd: this.config.working_dir,
+        cwd: this.runtimeConfig.working_dir,
         env,
         detached: this.detached,
         stdout: "pipe",
         stderr: "pipe",
       });
     } catch (error) {
+      if (generation !== this.startGeneration) return;
       this.lastExitCode = 1;
       this.lastSignal = null;
       this.setState("FAILED");
@@ -192,18 +251,22 @@ export class ServiceProcess {
       return;
     }
 
-    const processInfo = await readLiveProcessInfo(this.process.pid);
+    // Once spawned, the process stays tracked even if stopped meanwhile: no other start can
+    // begin until its exit handler clears it.
+    const processHandle = this.process;
+    const processInfo = await readLiveProcessInfo(processHandle.pid);
     this.startedAt = processInfo?.startedAt ?? timestamp();
     this.identityVerified = processInfo !== null;
-    this.setState("RUNNING");
-    this.attachStream(this.process.stdout, "stdout");
-    this.attachStream(this.process.stderr, "stderr");
-    this.process.exited
+    this.scheduleSettle(processHandle);
+    this.attachStream(processHandle.stdout, "stdout");
+    this.attachStream(processHandle.stderr, "stderr");
+    processHandle.exited
       .then((code) => {
+        this.clearSettleTimer();
         this.lastExitCode = code;
-        this.lastSignal = this.process?.signalCode ?? null;
+        this.lastSignal = processHandle.signalCode ?? null;
         this.process = null;
-        if (this.stopRequested) {
+        if (generation !== this.startGeneration) {
           this.setState("STOPPED");
         } else if (code === 0) {
           this.setState("STOPPED");
@@ -222,11 +285,11 @@ export class ServiceProcess {
   }
 
   async stop(signal: NodeJS.Signals = "SIGINT"): Promise<void> {
+    this.startGeneration += 1;
     if (!this.process) {
       this.setState("STOPPED");
       return;
     }
-    this.stopRequested = true;
     this.setState("STOPPING");
     try {
       this.signalProcess(signal);
@@ -235,12 +298,31 @@ export class ServiceProcess {
     }
   }
 
+  // Records that the supervisor gave up on a service that is no longer running, so the
+  // FAILED state comes from the process like any other transition.
+  markFailed(reason: string): void {
+    if (this.process) return;
+    this.setState("FAILED");
+    this.emit({
+      type: "log",
+      entry: { timestamp: timestamp(), line: reason, stream: "stderr" },
+    });
+  }
+
+  // Delivers a signal without treating it as a stop. A process still settling in STARTING can
+  // be signalled; returns false when nothing is running or a stop is already under way.
+  sendSignal(signal: NodeJS.Signals): boolean {
+    if (!this.process || this.state === "STOPPING") return false;
+    this.signalProcess(signal);
+    return true;
+  }
+
   async forceStop(signal: NodeJS.Signals = "SIGTERM"): Promise<void> {
+    this.startGeneration += 1;
     if (!this.process) {
       this.setState("STOPPED");
       return;
     }
-    this.stopRequested = true;
     this.setState("STOPPING");
     try {
       this.signalProcess(signal);
@@ -332,6 +414,21 @@ export class ServiceProcess {
     });
   }
 
+  private scheduleSettle(processHandle: Bun.Subprocess<"ignore", "pipe", "pipe">): void {
+    this.clearSettleTimer();
+    this.settleTimer = setTimeout(() => {
+      this.settleTimer = null;
+      if (this.process !== processHandle || this.state !== "STARTING") return;
+      this.setState("RUNNING");
+    }, STARTUP_SETTLE_MS);
+  }
+
+  private clearSettleTimer(): void {
+    if (!this.settleTimer) return;
+    clearTimeout(this.settleTimer);
+    this.settleTimer = null;
+  }
+
   private setState(state: ServiceState) {
     if (this.state === state) return;
     this.state = state;
@@ -0,0 +1,24 @@
+import { describe, expect, test } from "bun:test";
+import { formatSparkline, parseSignalName } from "./shared";
+
+describe("parseSignalName", () => {
+  test("accepts full, short and numeric signal names", () => {
+    expect(parseSignalName("SIGUSR1")).toBe("SIGUSR1");
+    expect(parseSignalName(" hup ")).toBe("SIGHUP");
+    expect(parseSignalName("15")).toBe("SIGTERM");
+  });
+
+  test("rejects unknown signals", () => {
+    expect(parseSignalName("SIGNOPE")).toBeNull();
+    expect(parseSignalName("999")).toBeNull();
+    expect(parseSignalName("")).toBeNull();
+  });
+});
+
+describe("formatSparkline", () => {
+  test("scales samples between the window's minimum and maximum", () => {
+    expect(formatSparkline([10, 20, 30])).toBe("▁▅█");
+    expect(formatSparkline([5, 5])).toBe("▁▁");
+    expect(formatSparkline([])).toBe("");
+  });
+});
@@ -1,3 +1,4 @@
+import { constants } from "node:os";
 import type { CommandSpec } from "./types";
 
 export const fileExists = async (path: string): Promise<boolean> => {
@@ -15,3 +16,43 @@ export const formatCommandSpec = (command: CommandSpec): string => {
   if (Array.isArray(command)) return command.join(" ");
   return command;
 };
+
+const DURATION_UNITS_MS: Record<string, number> = { ms: 1, s: 1000, m: 60_000, h: 3_600_000 };
+
+// Accepts "500ms", "30s", "2m", "1h", or a bare number of seconds.
+export const parseDuration = (value: string | number): number | null => {
+  if (typeof value === "number") {
+    return Number.isFinite(value) && value >= 0 ? value * 1000 : null;
+  }
+
+  const match = /^(\d+(?:\.\d+)?)(ms|s|m|h)?$/.exec(value.trim());
+  if (!match) return null;
+  const unit = DURATION_UNITS_MS[match[2] ?? "s"] ?? 1000;
+  return Number.parseFloat(match[1] ?? "0") * unit;
+};
+
+// Accepts "SIGUSR1", "usr1" or a signal number.
+export const parseSignalName = (value: string): NodeJS.Signals | null => {
+  const trimmed = value.trim().toUpperCase();
+  if (/^\d+$/.test(trimmed)) {
+    const number = Number(trimmed);
+    const match = Object.entries(constants.signals).find(([, signal]) => signal === number);
+    return match ? (match[0] as NodeJS.Signals) : null;
+  }
+
+  const name = trimmed.startsWith("SIG") ? trimmed : `SIG${trimmed}`;
+  return name in constants.signals ? (name as NodeJS.Signals) : null;
+};
+
+const SPARK_BLOCKS = "▁▂▃▄▅▆▇█";
+
+// Scales each value between the window's minimum and maximum so slow climbs still show.
+export const formatSparkline = (values: number[]): string => {
+  if (values.length === 0) return "";
+  const min = Math.min(...values);
+  const range = Math.max(...values) - min;
+  const top = SPARK_BLOCKS.length - 1;
+  return values
+    .map((value) => SPARK_BLOCKS[range === 0 ? 0 : Math.round(((value - min) / range) * top)])
+    .join("");
+};
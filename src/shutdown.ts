@@ -38,7 +38,7 @@ export const createShutdownHandler = ({
     shutdownPromise = (async () => {
       uninstall();
       if (reason) logger?.(reason);
-      await manager.stopAll();
+      await manager.stopAll({ keepDesiredState: true });
       const clean = await manager.waitForExit(EXIT_WAIT_MS);
       if (!clean) {
         await manager.forceStopAll();
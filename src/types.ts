@@ -1,16 +1,34 @@
 export type RestartPolicy = "never" | "on-failure" | "always";
 
+// STOPPED -> STARTING -> RUNNING -> STOPPING -> STOPPED. STARTING covers spawn plus a short
+// settle period; a process that exits non-zero before or after settling lands in FAILED, and a
+// requested stop or a clean exit lands in STOPPED.
 export type ServiceState = "STOPPED" | "STARTING" | "RUNNING" | "FAILED" | "STOPPING";
 
 export type CommandSpec = string | string[];
 
+export type ReloadSignal = "SIGHUP" | "SIGUSR1" | "SIGUSR2";
+
 export interface ServiceConfig {
   name: string;
   command: CommandSpec;
   working_dir?: string;
   env?: Record<string, string>;
+  env_file?: string[];
   restart_policy?: RestartPolicy;
+  restart_limit?: number;
+  restart_window?: string | number;
+  start_delay?: string | number;
+  // Resource budgets: CPU in percent of one core, memory in MiB.
+  cpu_alert?: number;
+  mem_alert?: number;
+  reload_signal?: ReloadSignal;
   depends_on?: string[];
+  profiles?: string[];
+  enabled?: boolean;
+  oneshot?: boolean;
+  // How long a oneshot may run before it is failed.
+  timeout?: string | number;
 }
 
 export interface AppDockerConfig {
@@ -19,12 +37,42 @@ export interface AppDockerConfig {
 
 export interface AppConfig {
   docker?: AppDockerConfig;
+  confirm_stop?: boolean;
+  log_lines?: number;
+  resource_history?: number;
+  log_timestamps?: LogTimestampFormat;
+  metrics?: string;
+  poll_interval?: string | number;
+  keys?: AppKeysConfig;
+  theme?: AppTheme;
+}
+
+// [app.keys.<panel>] tables map a shortcut label to the key that triggers it.
+export type AppKeysConfig = Partial<Record<PanelId, Record<string, string>>>;
+
+// What a manifest pulled in through `include`, kept so saves leave it in the included files.
+export interface ManifestIncludes {
+  paths: string[];
+  // Every service an included file defines, including ones the including file overrides.
+  names: string[];
+  services: ServiceConfig[];
+  vars: Record<string, string>;
 }
 
 export interface Manifest {
   app?: AppConfig;
+  vars?: Record<string, string>;
+  includes?: ManifestIncludes;
+  // [profiles.<name>] tables overlay [vars] while that profile is selected.
+  profiles?: Record<string, Record<string, string>>;
+  profile?: string;
+  // Disabled services and those tagged for other profiles; kept so saves do not drop them.
+  inactiveServices?: ServiceConfig[];
+  // Every service name in file order, so saves put services back where they were.
+  serviceOrder?: string[];
   services: ServiceConfig[];
   path: string;
+  warnings: string[];
 }
 
 export interface LogEntry {
@@ -33,6 +81,12 @@ export interface LogEntry {
   stream: "stdout" | "stderr";
 }
 
+export type LogStreamFilter = "all" | LogEntry["stream"];
+
+export type LogTimestampFormat = "clock" | "precise" | "relative";
+
+export type AppTheme = "auto" | "dark" | "light" | "high-contrast" | "mono";
+
 export interface ServicePid {
   name: string;
   pid: number;
@@ -54,9 +108,13 @@ export type DockerServiceState =
   | "removing"
   | "unknown";
 
+export type DockerHealth = "starting" | "healthy" | "unhealthy";
+
 export interface DockerService {
   name: string;
   state: DockerServiceState;
+  // null when the image defines no healthcheck.
+  health: DockerHealth | null;
   status: string;
   ports: string;
 }
@@ -66,4 +124,17 @@ export interface Shortcut {
   label: string;
 }
 
-export type AppMode = "normal" | "editing" | "adding" | "discovering";
+export type AppMode =
+  | "normal"
+  | "editing"
+  | "adding"
+  | "discovering"
+  | "searching"
+  | "signaling"
+  | "enabling"
+  | "help";
+
+export interface ShortcutSection {
+  title: string;
+  shortcuts: Shortcut[];
+}
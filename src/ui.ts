@@ -12,8 +12,18 @@ import type { DiscoverySelection, SelectionItem } from "./discovery";
 import type { DockerManager } from "./docker";
 import type { FocusManager } from "./focus";
 import type { ServiceManager, ServiceView } from "./service-manager";
-import { formatCommandSpec } from "./shared";
-import type { DockerService, LogEntry, Manifest, PanelId, Shortcut } from "./types";
+import { formatCommandSpec, formatSparkline } from "./shared";
+import type {
+  AppTheme,
+  DockerService,
+  LogEntry,
+  LogStreamFilter,
+  LogTimestampFormat,
+  Manifest,
+  PanelId,
+  Shortcut,
+  ShortcutSection,
+} from "./types";
 
 interface Palette {
   active: string;
@@ -81,7 +91,68 @@ const light: Palette = {
   inputFocus: "#f5f5f5",
 };
 
-const getTheme = (mode: "dark" | "light" | null): Palette => (mode === "light" ? light : dark);
+// Running and failed states use blue and orange so red-green colorblindness keeps them apart.
+const highContrast: Palette = {
+  active: "#ffffff",
+  muted: "#c6c6c6",
+  panel: "#000000",
+  panelActive: "#1c1c1c",
+  selection: "#3a3a3a",
+  hover: "#262626",
+  element: "#0c0c0c",
+  accent: "#ffd75f",
+  secondary: "#5fd7ff",
+  amber: "#ffaf00",
+  green: "#5fafff",
+  red: "#ff8700",
+  bg: "transparent",
+  border: "#bcbcbc",
+  borderActive: "#ffffff",
+  overlay: RGBA.fromInts(0, 0, 0, 0),
+  modal: "#000000",
+  input: "#121212",
+  inputFocus: "#262626",
+};
+
+// Grayscale only; states stay readable through their text labels.
+const mono: Palette = {
+  active: "#eeeeee",
+  muted: "#8a8a8a",
+  panel: "#161616",
+  panelActive: "#222222",
+  selection: "#3a3a3a",
+  hover: "#262626",
+  element: "#1d1d1d",
+  accent: "#ffffff",
+  secondary: "#bcbcbc",
+  amber: "#d0d0d0",
+  green: "#eeeeee",
+  red: "#ffffff",
+  bg: "transparent",
+  border: "#484848",
+  borderActive: "#8a8a8a",
+  overlay: RGBA.fromInts(0, 0, 0, 0),
+  modal: "#141414",
+  input: "#1e1e1e",
+  inputFocus: "#282828",
+};
+
+// An explicit [app] theme wins. "auto" follows the terminal unless NO_COLOR is set.
+const getTheme = (mode: "dark" | "light" | null, theme: AppTheme = "auto"): Palette => {
+  switch (theme) {
+    case "dark":
+      return dark;
+    case "light":
+      return light;
+    case "high-contrast":
+      return highContrast;
+    case "mono":
+      return mono;
+    default:
+      if (process.env.NO_COLOR) return mono;
+      return mode === "light" ? light : dark;
+  }
+};
 const VERSION_LABEL = "Stasium v0.2.3 (32423)";
 const APP_INSET_X = 2;
 const APP_INSET_Y = 1;
@@ -96,11 +167,15 @@ const COMPACT_GAP = 0;
 const INPUT_PADDING_X = 1;
 const SCROLLBAR_PADDING_RIGHT = 1;
 const LOG_ROW_GAP_X = 1;
-const LOG_TIMESTAMP_WIDTH = 8;
+const LOG_TIMESTAMP_WIDTHS: Record<LogTimestampFormat, number> = {
+  clock: 8,
+  precise: 12,
+  relative: 8,
+};
 const LOG_STREAM_WIDTH = 3;
 const LOG_MIN_MESSAGE_WIDTH = 4;
-const LOG_DETAIL_PADDING_LEFT = LOG_TIMESTAMP_WIDTH + LOG_STREAM_WIDTH + LOG_ROW_GAP_X * 2;
 const MIN_LOG_PANEL_WIDTH = 56;
+const FOOTER_NOTICE_MS = 5000;
 const MIN_APP_WIDTH = 80;
 const MIN_APP_HEIGHT_WITH_DOCKER = 35;
 const MIN_APP_HEIGHT_NO_DOCKER = 28;
@@ -137,15 +212,40 @@ const dockerStateColor = (state: DockerService["state"], palette: Palette): stri
   }
 };
 
-const formatState = (state: ServiceView["state"]) => state.padEnd(8, " ");
+// A run that exited 0 on its own reads as DONE, so a finished oneshot is not mistaken for stopped.
+const formatState = (view: ServiceView) =>
+  (view.state === "STOPPED" && view.completed ? "DONE" : view.state).padEnd(8, " ");
+
+// Running containers with a healthcheck show its result instead of the plain state.
+const dockerDisplayState = (service: DockerService): string =>
+  service.state === "running" && service.health ? service.health : service.state;
+
+const dockerServiceColor = (service: DockerService, palette: Palette): string => {
+  if (service.state !== "running") return dockerStateColor(service.state, palette);
+  if (service.health === "unhealthy") return palette.red;
+  if (service.health === "starting") return palette.amber;
+  return palette.green;
+};
 
-const formatDockerState = (state: DockerService["state"]) => state.padEnd(10, " ");
+const formatDockerState = (service: DockerService) => dockerDisplayState(service).padEnd(10, " ");
 
 const formatExit = (exit: number | null) => {
   if (exit === null) return "--";
   return String(exit);
 };
 
+const formatBytes = (bytes: number): string => {
+  if (bytes >= 1024 ** 3) return `${(bytes / 1024 ** 3).toFixed(1)}G`;
+  if (bytes >= 1024 ** 2) return `${Math.round(bytes / 1024 ** 2)}M`;
+  return `${Math.round(bytes / 1024)}K`;
+};
+
+const formatUsage = (view: ServiceView): string => {
+  const cpu = view.cpuPercent === null ? "--" : `${Math.round(view.cpuPercent)}%`;
+  const mem = view.memBytes === null ? "--" : formatBytes(view.memBytes);
+  return `cpu:${cpu} mem:${mem}`;
+};
+
 const clamp = (value: number, min: number, max: number): number =>
   Math.min(Math.max(value, min), max);
 
@@ -164,11 +264,15 @@ const padRight = (value: string, width: number): string => {
 const formatManifestLine = (view: ServiceView, selected: boolean, rowWidth: number): string => {
   if (rowWidth <= 0) return "";
   const prefix = selected ? ">" : " ";
-  const status = formatState(view.state);
+  const status = formatState(view);
   const meta =
     view.restartInMs !== null
       ? `retry:${Math.ceil(view.restartInMs)}ms rst:${view.restartCount}`
-      : `exit:${formatExit(view.lastExitCode)} rst:${view.restartCount}`;
+      : view.alerts.length > 0
+        ? `!${view.alerts.join("!")} ${formatUsage(view)}`
+        : view.memBytes !== null
+          ? `${formatUsage(view)} rst:${view.restartCount}`
+          : `exit:${formatExit(view.lastExitCode)} rst:${view.restartCount}`;
 
   const baseWidth = 2 + status.length + 1;
   const metaWidth = rowWidth >= 56 ? 22 : rowWidth >= 46 ? 16 : 0;
@@ -186,7 +290,7 @@ const formatManifestLine = (view: ServiceView, selected: boolean, rowWidth: numb
 const formatDockerLine = (service: DockerService, selected: boolean, rowWidth: number): string => {
   if (rowWidth <= 0) return "";
   const prefix = selected ? ">" : " ";
-  const status = formatDockerState(service.state);
+  const status = formatDockerState(service);
   const meta = service.ports ? `ports:${service.ports}` : service.status;
 
   const baseWidth = 2 + status.length + 1;
@@ -224,9 +328,28 @@ const ensureIndexVisible = (box: ScrollBoxRenderable, index: number): void => {
 const getScrollBoxMaxTop = (box: ScrollBoxRenderable): number =>
   Math.max(0, box.scrollHeight - Math.max(1, Math.floor(box.viewport.height)));
 
-const formatLogTimestamp = (value: string): string => {
-  const time = value.slice(11, 19);
-  return time.length === LOG_TIMESTAMP_WIDTH ? time : truncateText(value, LOG_TIMESTAMP_WIDTH);
+const formatLogDelta = (ms: number): string => {
+  const seconds = Math.max(0, ms) / 1000;
+  if (seconds < 100) return `+${seconds.toFixed(1)}s`;
+  if (seconds < 6000) return `+${Math.floor(seconds / 60)}m`;
+  return `+${Math.floor(seconds / 3600)}h`;
+};
+
+// Relative stamps measure the gap since the previous visible line, so bursts read as +0.0s.
+const formatLogTimestamp = (
+  value: string,
+  format: LogTimestampFormat,
+  previous: string | undefined,
+): string => {
+  const width = LOG_TIMESTAMP_WIDTHS[format];
+  if (format === "relative") {
+    if (previous === undefined) return formatLogDelta(0);
+    const delta = Date.parse(value) - Date.parse(previous);
+    return Number.isFinite(delta) ? formatLogDelta(delta) : truncateText(value, width);
+  }
+
+  const time = value.slice(11, 11 + width);
+  return time.length === width ? time : truncateText(value, width);
 };
 
 const formatLogStream = (stream: LogEntry["stream"]): string =>
@@ -277,8 +400,18 @@ export interface UiControls {
   getAddCommand: () => string;
   setAddError: (message: string) => void;
   clearAddError: () => void;
-  showDeleteConfirm: (name: string) => void;
-  hideDeleteConfirm: () => void;
+  showConfirm: (title: string, message: string) => void;
+  hideConfirm: () => void;
+  showHelp: (sections: ShortcutSection[]) => void;
+  hideHelp: () => void;
+  showSignalPrompt: (name: string) => void;
+  hideSignalPrompt: () => void;
+  getSignalInput: () => string;
+  setSignalError: (message: string) => void;
+  showEnablePrompt: (names: string[]) => void;
+  hideEnablePrompt: () => void;
+  getEnableInput: () => string;
+  setEnableError: (message: string) => void;
   showDiscoveryOverlay: (selection: DiscoverySelection, warnings: string[]) => void;
   hideDiscoveryOverlay: () => void;
   setDiscoveryError: (message: string) => void;
@@ -290,6 +423,16 @@ export interface UiControls {
   scrollLogsToTop: () => void;
   scrollLogsToBottom: () => void;
   toggleLogsFollowTail: () => boolean;
+  showLogSearch: () => void;
+  hideLogSearch: () => void;
+  getLogSearchInput: () => string;
+  setLogSearch: (query: string) => void;
+  moveLogMatch: (delta: number) => boolean;
+  cycleLogStreamFilter: () => LogStreamFilter;
+  toggleLogMatchesOnly: () => boolean;
+  toggleLogTimestamps: () => boolean;
+  getActiveLogExport: () => { name: string; text: string } | null;
+  showNotice: (message: string, isError?: boolean) => void;
   getLogsFollowTail: () => boolean;
   setLogsFollowTail: (enabled: boolean) => void;
   clearLogs: () => void;
@@ -299,7 +442,8 @@ export interface UiControls {
 export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiControls } => {
   const { renderer, manifest, manager, focusManager, dockerManager } = opts;
   const hasDocker = dockerManager !== null;
-  let palette = getTheme(renderer.themeMode);
+  const theme = manifest.app?.theme;
+  let palette = getTheme(renderer.themeMode, theme);
 
   const root = new BoxRenderable(renderer, {
     width: "100%",
@@ -528,6 +672,33 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
       },
     },
   });
+
+  const logSearchRow = new BoxRenderable(renderer, {
+    flexDirection: "row",
+    columnGap: INLINE_GAP_X,
+    flexShrink: 0,
+    backgroundColor: palette.input,
+    paddingX: INPUT_PADDING_X,
+    visible: false,
+  });
+
+  const logSearchPrefix = new TextRenderable(renderer, {
+    content: "/",
+    fg: palette.amber,
+  });
+  logSearchRow.add(logSearchPrefix);
+
+  const logSearchInput = new InputRenderable(renderer, {
+    id: "log-search",
+    placeholder: "search logs",
+    backgroundColor: palette.input,
+    textColor: palette.active,
+    focusedBackgroundColor: palette.inputFocus,
+    flexGrow: 1,
+  });
+  logSearchRow.add(logSearchInput);
+
+  logPanel.add(logSearchRow);
   logPanel.add(logList);
 
   sideColumn.add(manifestPanel);
@@ -597,6 +768,9 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
   const compactShortcutLabels: Record<string, string> = {
     "switch panel": "switch",
     "next field": "next",
+    "next match": "next",
+    "matches only": "only",
+    "save logs": "save",
     follow: "tail",
     discover: "scan",
     "manifest panel": "manifest",
@@ -614,9 +788,24 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
     page: 75,
     clear: 75,
     follow: 78,
+    search: 74,
+    "next match": 68,
+    stream: 66,
+    "matches only": 64,
+    time: 62,
+    "save logs": 60,
+    pause: 70,
+    unpause: 70,
+    remove: 68,
+    "reload svc": 66,
+    signal: 64,
+    send: 95,
+    copy: 58,
     add: 70,
     discover: 72,
     delete: 70,
+    disable: 56,
+    enable: 56,
     edit: 70,
     move: 85,
     toggle: 85,
@@ -627,6 +816,8 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
     "logs panel": 60,
     "all panels": 65,
     "switch panel": 95,
+    help: 96,
+    close: 95,
     quit: 100,
     confirm: 95,
     cancel: 95,
@@ -761,6 +952,11 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
       },
     ];
 
+    if (hasDocker && dockerManager?.getOfflineReason()) {
+      segments.push({ content: "docker offline", fg: palette.red, panel: "docker" });
+      return segments;
+    }
+
     if (hasDocker && dockerManager) {
       const dockerServices = dockerManager.getServices();
       const dockerRunning = dockerServices.filter((service) => service.state === "running").length;
@@ -774,6 +970,17 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
         panel: "docker",
       });
 
+      const dockerUnhealthy = dockerServices.filter(
+        (service) => service.state === "running" && service.health === "unhealthy",
+      ).length;
+      if (dockerUnhealthy > 0) {
+        segments.push({
+          content: `${dockerUnhealthy} unhealthy`,
+          fg: palette.red,
+          panel: "docker",
+        });
+      }
+
       segments.push({
         content: `${dockerStopped} stopped`,
         fg: dockerStopped > 0 ? palette.red : palette.muted,
@@ -861,6 +1068,37 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
       ];
     }
 
+    if (mode === "signaling") {
+      return [
+        { content: "sending signal", fg: palette.accent },
+        { content: "enter send", fg: palette.secondary },
+        { content: "esc cancel", fg: palette.muted },
+      ];
+    }
+
+    if (mode === "enabling") {
+      return [
+        { content: "enabling service", fg: palette.accent },
+        { content: "enter enable", fg: palette.secondary },
+        { content: "esc cancel", fg: palette.muted },
+      ];
+    }
+
+    if (mode === "searching") {
+      return [
+        { content: "searching logs", fg: palette.accent },
+        { content: "enter search", fg: palette.secondary },
+        { content: "esc clear", fg: palette.muted },
+      ];
+    }
+
+    if (mode === "help") {
+      return [
+        { content: "keybinding help", fg: palette.accent },
+        { content: "esc/? close", fg: palette.muted },
+      ];
+    }
+
     const activePanel = focusManager.getActivePanel();
     const visiblePanels = getRenderedPanels();
     const requestedPanels = focusManager.getVisiblePanels();
@@ -871,8 +1109,19 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
         ? (selectedDocker?.name ?? "docker")
         : (selectedManifest?.name ?? "service");
     const tailState = logsFollowTail ? "tail:on" : "tail:paused";
-    const manifestState = selectedManifest?.state.toLowerCase() ?? "none";
-    const dockerState = selectedDocker?.state ?? "none";
+    const manifestDetails = [selectedManifest?.state.toLowerCase() ?? "none"];
+    if (selectedManifest && selectedManifest.pids.length > 1) {
+      manifestDetails.push(`${selectedManifest.pids.length} pids`);
+    }
+    if (selectedManifest && selectedManifest.ports.length > 0) {
+      manifestDetails.push(`:${selectedManifest.ports.join(",")}`);
+    }
+    const manifestState = manifestDetails.join(", ");
+    const cpuTrend = formatSparkline(selectedManifest?.cpuHistory ?? []);
+    const memTrend = formatSparkline(selectedManifest?.memHistory ?? []);
+    const usageTrend = memTrend.length > 1 ? `cpu ${cpuTrend} mem ${memTrend}` : null;
+    const dockerState = selectedDocker ? dockerDisplayState(selectedDocker) : "none";
+    const dockerOffline = dockerManager?.getOfflineReason() ?? null;
 
     const segments = [
       { content: `layout:${formatVisiblePanels(visiblePanels)}`, fg: palette.secondary },
@@ -881,10 +1130,16 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
         content: `svc:${selectedManifest?.name ?? "-"} (${manifestState})`,
         fg: selectedManifest ? stateColor(selectedManifest.state, palette) : palette.muted,
       },
-      {
-        content: `docker:${selectedDocker?.name ?? "-"} (${dockerState})`,
-        fg: selectedDocker ? dockerStateColor(selectedDocker.state, palette) : palette.muted,
-      },
+      ...(usageTrend ? [{ content: usageTrend, fg: palette.secondary }] : []),
+      dockerOffline
+        ? {
+            content: `docker:offline (${dockerOffline}; is the Docker daemon running?)`,
+            fg: palette.red,
+          }
+        : {
+            content: `docker:${selectedDocker?.name ?? "-"} (${dockerState})`,
+            fg: selectedDocker ? dockerServiceColor(selectedDocker, palette) : palette.muted,
+          },
       {
         content: logsPanelVisible ? `logs:${activeLogName} ${tailState}` : "logs:hidden",
         fg: logsPanelVisible ? (logsFollowTail ? palette.secondary : palette.muted) : palette.muted,
@@ -895,6 +1150,10 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
       segments.push({ content: "logs:auto-hidden", fg: palette.amber });
     }
 
+    if (footerNotice) {
+      segments.push(footerNotice);
+    }
+
     return segments;
   };
 
@@ -1161,8 +1420,8 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
   });
   discoveryOverlay.add(discoveryError);
 
-  const deleteOverlay = new BoxRenderable(renderer, {
-    id: "delete-overlay",
+  const confirmOverlay = new BoxRenderable(renderer, {
+    id: "confirm-overlay",
     width: 56,
     backgroundColor: palette.modal,
     flexDirection: "column",
@@ -1174,18 +1433,141 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
     visible: false,
   });
 
-  const deleteTitle = new TextRenderable(renderer, {
-    content: "Delete service",
+  const confirmTitle = new TextRenderable(renderer, {
+    content: "Confirm",
     fg: palette.red,
     attributes: TextAttributes.BOLD,
   });
-  deleteOverlay.add(deleteTitle);
+  confirmOverlay.add(confirmTitle);
+
+  const confirmMessage = new TextRenderable(renderer, {
+    content: "Are you sure? (y/n)",
+    fg: palette.active,
+  });
+  confirmOverlay.add(confirmMessage);
+
+  const helpOverlay = new BoxRenderable(renderer, {
+    id: "help-overlay",
+    width: 78,
+    backgroundColor: palette.modal,
+    flexDirection: "column",
+    paddingTop: PANEL_PADDING_Y,
+    paddingBottom: PANEL_PADDING_Y,
+    paddingLeft: PANEL_PADDING_X,
+    paddingRight: PANEL_PADDING_X,
+    rowGap: PANEL_CONTENT_GAP_Y,
+    visible: false,
+  });
+
+  const helpTitle = new TextRenderable(renderer, {
+    content: "Keybindings (esc or ? to close)",
+    fg: palette.accent,
+    attributes: TextAttributes.BOLD,
+  });
+  helpOverlay.add(helpTitle);
 
-  const deleteMessage = new TextRenderable(renderer, {
-    content: "Delete selected service? (y/n)",
+  const helpBody = new TextRenderable(renderer, {
+    content: "",
     fg: palette.active,
+    wrapMode: "word",
   });
-  deleteOverlay.add(deleteMessage);
+  helpOverlay.add(helpBody);
+
+  const signalOverlay = new BoxRenderable(renderer, {
+    id: "signal-overlay",
+    width: 56,
+    backgroundColor: palette.modal,
+    flexDirection: "column",
+    paddingTop: PANEL_PADDING_Y,
+    paddingBottom: PANEL_PADDING_Y,
+    paddingLeft: PANEL_PADDING_X,
+    paddingRight: PANEL_PADDING_X,
+    rowGap: PANEL_CONTENT_GAP_Y,
+    visible: false,
+  });
+
+  const signalTitle = new TextRenderable(renderer, {
+    content: "Send signal",
+    fg: palette.accent,
+    attributes: TextAttributes.BOLD,
+  });
+  signalOverlay.add(signalTitle);
+
+  const signalField = new BoxRenderable(renderer, {
+    width: "100%",
+    backgroundColor: palette.inputFocus,
+    paddingX: INPUT_PADDING_X,
+  });
+
+  const signalInput = new InputRenderable(renderer, {
+    id: "signal-name",
+    placeholder: "e.g. SIGUSR1, hup, 10",
+    backgroundColor: palette.input,
+    textColor: palette.active,
+    focusedBackgroundColor: palette.inputFocus,
+    width: "100%",
+  });
+  signalField.add(signalInput);
+  signalOverlay.add(signalField);
+
+  const signalError = new TextRenderable(renderer, {
+    content: "",
+    fg: palette.red,
+    wrapMode: "none",
+    truncate: true,
+  });
+  signalOverlay.add(signalError);
+
+  const enableOverlay = new BoxRenderable(renderer, {
+    id: "enable-overlay",
+    width: 56,
+    backgroundColor: palette.modal,
+    flexDirection: "column",
+    paddingTop: PANEL_PADDING_Y,
+    paddingBottom: PANEL_PADDING_Y,
+    paddingLeft: PANEL_PADDING_X,
+    paddingRight: PANEL_PADDING_X,
+    rowGap: PANEL_CONTENT_GAP_Y,
+    visible: false,
+  });
+
+  const enableTitle = new TextRenderable(renderer, {
+    content: "Enable service (enter enable, esc cancel)",
+    fg: palette.accent,
+    attributes: TextAttributes.BOLD,
+  });
+  enableOverlay.add(enableTitle);
+
+  const enableNames = new TextRenderable(renderer, {
+    content: "",
+    fg: palette.muted,
+  });
+  enableOverlay.add(enableNames);
+
+  const enableField = new BoxRenderable(renderer, {
+    width: "100%",
+    backgroundColor: palette.inputFocus,
+    paddingX: INPUT_PADDING_X,
+  });
+
+  const enableInput = new InputRenderable(renderer, {
+    id: "enable-name",
+    placeholder: "service name",
+    backgroundColor: palette.input,
+    textColor: palette.active,
+    focusedBackgroundColor: palette.inputFocus,
+    width: "100%",
+  });
+  enableField.add(enableInput);
+  enableOverlay.add(enableField);
+
+  const enableError = new TextRenderable(renderer, {
+    content: "",
+    fg: palette.red,
+    wrapMode: "none",
+    truncate: true,
+  });
+  enableOverlay.add(enableError);
 
   const tooSmallOverlay = new BoxRenderable(renderer, {
     id: "too-small-overlay",
@@ -1287,7 +1669,10 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
   overlayBg.add(editOverlay);
   overlayBg.add(addOverlay);
   overlayBg.add(discoveryOverlay);
-  overlayBg.add(deleteOverlay);
+  overlayBg.add(confirmOverlay);
+  overlayBg.add(helpOverlay);
+  overlayBg.add(signalOverlay);
+  overlayBg.add(enableOverlay);
 
   root.add(overlayBg);
   root.add(tooSmallOverlay);
@@ -1305,6 +1690,13 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
   let hoveredLogEntryKey: string | null = null;
   let selectedLogEntryKey: string | null = null;
   let expandedLogEntryKey: string | null = null;
+  let logSearchQuery = "";
+  let logStreamFilter: LogStreamFilter = "all";
+  let logMatchesOnly = false;
+  let logTimestampsVisible = true;
+  let footerNotice: { content: string; fg: string } | null = null;
+  let footerNoticeTimer: ReturnType<typeof setTimeout> | null = null;
+  const logTimestampFormat: LogTimestampFormat = manifest.app?.log_timestamps ?? "clock";
   let hoveredManifestIndex = -1;
   let hoveredDockerIndex = -1;
   let addFocusField: "name" | "command" = "name";
@@ -1409,13 +1801,39 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
     renderAll();
   };
 
+  // Matching is a case-insensitive substring test over the raw line.
+  const matchesLogSearch = (entry: LogEntry): boolean =>
+    logSearchQuery !== "" && entry.line.toLowerCase().includes(logSearchQuery.toLowerCase());
+
+  const filterLogEntries = (entries: LogEntry[]): LogEntry[] => {
+    const matchesOnly = logMatchesOnly && logSearchQuery !== "";
+    if (logStreamFilter === "all" && !matchesOnly) return entries;
+    return entries.filter(
+      (entry) =>
+        (logStreamFilter === "all" || entry.stream === logStreamFilter) &&
+        (!matchesOnly || matchesLogSearch(entry)),
+    );
+  };
+
   const getActiveLogEntries = (): LogEntry[] => {
     const source = logSource === "docker" && dockerManager ? "docker" : "manifest";
     const buffer =
       source === "docker"
         ? (dockerManager?.getActiveLogBuffer() ?? null)
         : (manager.getSelectedView()?.log ?? null);
-    return buffer?.all() ?? [];
+    return filterLogEntries(buffer?.all() ?? []);
+  };
+
+  const selectLogIndex = (entries: LogEntry[], index: number): void => {
+    const entry = entries[index];
+    if (!entry) return;
+
+    activatePanel("logs");
+    selectedLogEntryKey = getLogEntryKey(entry, index);
+    expandedLogEntryKey = selectedLogEntryKey;
+    logsFollowTail = false;
+    invalidateLogs();
+    renderAll();
   };
 
   const moveLogSelection = (delta: number): void => {
@@ -1431,15 +1849,28 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
           ? entries.length - 1
           : 0
         : clamp(currentIndex + delta, 0, entries.length - 1);
-    const nextEntry = entries[nextIndex];
-    if (!nextEntry) return;
+    selectLogIndex(entries, nextIndex);
+  };
 
-    activatePanel("logs");
-    selectedLogEntryKey = getLogEntryKey(nextEntry, nextIndex);
-    expandedLogEntryKey = selectedLogEntryKey;
-    logsFollowTail = false;
-    invalidateLogs();
-    renderAll();
+  const moveLogMatch = (delta: number): boolean => {
+    const entries = getActiveLogEntries();
+    if (entries.length === 0 || logSearchQuery === "") return false;
+
+    const currentIndex = selectedLogEntryKey
+      ? entries.findIndex((entry, index) => getLogEntryKey(entry, index) === selectedLogEntryKey)
+      : -1;
+    // Without a selection the search starts from the tail, where the newest lines are.
+    const start = currentIndex === -1 ? (delta < 0 ? entries.length : -1) : currentIndex;
+    const step = delta < 0 ? -1 : 1;
+    for (let offset = 1; offset <= entries.length; offset += 1) {
+      const index = (start + step * offset + entries.length * 2) % entries.length;
+      const entry = entries[index];
+      if (entry && matchesLogSearch(entry)) {
+        selectLogIndex(entries, index);
+        return true;
+      }
+    }
+    return false;
   };
 
   const focusWhenVisible = (target: BoxRenderable, focus: () => void): void => {
@@ -1457,7 +1888,10 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
       editOverlay.visible ||
       addOverlay.visible ||
       discoveryOverlay.visible ||
-      deleteOverlay.visible;
+      confirmOverlay.visible ||
+      helpOverlay.visible ||
+      signalOverlay.visible ||
+      enableOverlay.visible;
 
     tooSmallOverlay.visible = tooSmall;
     header.visible = !tooSmall;
@@ -1539,7 +1973,7 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
 
       const timestamp = new TextRenderable(renderer, {
         id: `log-row-ts-${index}`,
-        width: LOG_TIMESTAMP_WIDTH,
+        width: LOG_TIMESTAMP_WIDTHS[logTimestampFormat],
         fg: palette.muted,
         wrapMode: "none",
         truncate: true,
@@ -1707,7 +2141,11 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
       const line = listLines[index];
       if (!line) return;
       line.content = formatManifestLine(view, selected, rowWidth);
-      line.fg = selected ? palette.active : stateColor(view.state, palette);
+      line.fg = selected
+        ? palette.active
+        : view.alerts.length > 0
+          ? palette.amber
+          : stateColor(view.state, palette);
       line.bg = listRowBackground("manifest", selected, index === hoveredManifestIndex);
       line.onMouseDown = (event) => {
         event.stopPropagation();
@@ -1728,6 +2166,7 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
 
     const services = dockerManager.getServices();
     const selectedIdx = dockerManager.getSelectedIndex();
+    const offline = dockerManager.getOfflineReason() !== null;
     dockerLines = syncRows(dockerList, dockerLines, services.length, "docker");
 
     const viewportWidth = Math.floor(dockerList.viewport.width);
@@ -1738,7 +2177,12 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
       const line = dockerLines[index];
       if (!line) return;
       line.content = formatDockerLine(service, selected, rowWidth);
-      line.fg = selected ? palette.active : dockerStateColor(service.state, palette);
+      // Rows from the last successful refresh stay listed but dimmed while docker is offline.
+      line.fg = offline
+        ? palette.muted
+        : selected
+          ? palette.active
+          : dockerServiceColor(service, palette);
       line.bg = listRowBackground("docker", selected, index === hoveredDockerIndex);
       line.onMouseDown = (event) => {
         event.stopPropagation();
@@ -1750,9 +2194,8 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
       };
     });
 
-    dockerPanelMeta.content = `${services.filter((service) => service.state === "running").length}/${
-      services.length
-    } running`;
+    const running = services.filter((service) => service.state === "running").length;
+    dockerPanelMeta.content = offline ? "offline, retrying" : `${running}/${services.length} running`;
     ensureIndexVisible(dockerList, selectedIdx);
   };
 
@@ -1784,7 +2227,8 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
     lastSelectedIndex = selectedIndex;
     lastLogSource = source;
 
-    const entries = buffer?.all() ?? [];
+    const bufferedEntries = buffer?.all() ?? [];
+    const entries = filterLogEntries(bufferedEntries);
     const entryKeys = new Set(entries.map((entry, index) => getLogEntryKey(entry, index)));
     if (hoveredLogEntryKey && !entryKeys.has(hoveredLogEntryKey)) hoveredLogEntryKey = null;
     if (selectedLogEntryKey && !entryKeys.has(selectedLogEntryKey)) selectedLogEntryKey = null;
@@ -1794,6 +2238,9 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
 
     const viewportWidth = Math.floor(logList.viewport.width);
     const rowWidth = Math.max(24, viewportWidth > 0 ? viewportWidth - 1 : 64);
+    const timestampWidth = LOG_TIMESTAMP_WIDTHS[logTimestampFormat];
+    const timestampColumn = logTimestampsVisible ? timestampWidth + LOG_ROW_GAP_X : 0;
+    const detailPadding = timestampColumn + LOG_STREAM_WIDTH + LOG_ROW_GAP_X;
 
     entries.forEach((entry, index) => {
       const row = logLines[index];
@@ -1803,10 +2250,11 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
       const selected = selectedLogEntryKey === key;
       const expanded = expandedLogEntryKey === key;
       const backgroundColor = logRowBackground(key);
+      const matched = matchesLogSearch(entry);
       row.entryKey = key;
       const metaBase = `#${index + 1}`;
       const reservedWidth =
-        LOG_TIMESTAMP_WIDTH + LOG_STREAM_WIDTH + metaBase.length + LOG_ROW_GAP_X * 3;
+        timestampColumn + LOG_STREAM_WIDTH + metaBase.length + LOG_ROW_GAP_X * 2;
       const messageWidth = Math.max(LOG_MIN_MESSAGE_WIDTH, rowWidth - reservedWidth);
       const truncated = truncateLogMessage(entry.line, messageWidth);
       const metaText = expanded
@@ -1823,15 +2271,24 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
       row.box.paddingRight = expanded ? PANEL_CONTENT_GAP_Y : 0;
       row.box.marginY = expanded ? PANEL_CONTENT_GAP_Y : 0;
       row.summary.backgroundColor = backgroundColor;
-      row.timestamp.content = formatLogTimestamp(entry.timestamp);
+      row.timestamp.content = formatLogTimestamp(
+        entry.timestamp,
+        logTimestampFormat,
+        entries[index - 1]?.timestamp,
+      );
       row.timestamp.fg = palette.muted;
+      row.timestamp.visible = logTimestampsVisible;
       row.stream.content = formatLogStream(entry.stream);
       row.stream.fg = entry.stream === "stderr" ? palette.red : palette.secondary;
       row.message.content = truncated.text;
-      row.message.fg = entry.stream === "stderr" ? palette.red : palette.active;
+      row.message.fg = matched
+        ? palette.amber
+        : entry.stream === "stderr"
+          ? palette.red
+          : palette.active;
       row.meta.content = metaText;
       row.meta.fg = truncated.hidden > 0 ? palette.amber : palette.muted;
-      row.detail.content = `${" ".repeat(LOG_DETAIL_PADDING_LEFT)}${entry.line}`;
+      row.detail.content = `${" ".repeat(detailPadding)}${entry.line}`;
       row.detail.fg = entry.stream === "stderr" ? palette.red : palette.active;
       row.detail.visible = expanded;
       row.detail.bg = backgroundColor;
@@ -1869,15 +2326,28 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
           );
     const maxTop = getScrollBoxMaxTop(logList);
     const scroll = maxTop === 0 ? 100 : Math.round((logList.scrollTop / maxTop) * 100);
+    const lineCount =
+      entries.length === bufferedEntries.length
+        ? `${entries.length}`
+        : `${entries.length}/${bufferedEntries.length}`;
+    const filterMeta = [
+      logStreamFilter === "all" ? "" : `  stream:${logStreamFilter}`,
+      logMatchesOnly && logSearchQuery !== "" ? "  only:matches" : "",
+      buffer && buffer.getDroppedCount() > 0 ? `  dropped:${buffer.getDroppedCount()}` : "",
+    ].join("");
+    const searchMeta =
+      logSearchQuery === ""
+        ? ""
+        : `  find:${logSearchQuery} ${entries.filter(matchesLogSearch).length} hits`;
 
     if (source === "docker") {
       const selected = dockerManager?.getSelectedService();
-      logPanelMeta.content = `${selected?.name ?? "docker"}  lines:${entries.length}  show:${visibleStart}-${visibleEnd}  ${logsFollowTail ? "tail:on" : "tail:off"}  scroll:${scroll}%`;
+      logPanelMeta.content = `${selected?.name ?? "docker"}  lines:${lineCount}${filterMeta}${searchMeta}  show:${visibleStart}-${visibleEnd}  ${logsFollowTail ? "tail:on" : "tail:off"}  scroll:${scroll}%`;
       return;
     }
 
     const selected = manager.getSelectedView();
-    logPanelMeta.content = `${selected?.name ?? "service"}  lines:${entries.length}  show:${visibleStart}-${visibleEnd}  ${logsFollowTail ? "tail:on" : "tail:off"}  scroll:${scroll}%`;
+    logPanelMeta.content = `${selected?.name ?? "service"}  lines:${lineCount}${filterMeta}${searchMeta}  show:${visibleStart}-${visibleEnd}  ${logsFollowTail ? "tail:on" : "tail:off"}  scroll:${scroll}%`;
   };
 
   const updatePanelStyles = () => {
@@ -2005,13 +2475,16 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
     editOverlay.height = compactOverlay ? "82%" : "68%";
     addOverlay.width = compactOverlay ? "92%" : 60;
     discoveryOverlay.width = compactOverlay ? "94%" : 78;
-    deleteOverlay.width = compactOverlay ? "88%" : 56;
+    confirmOverlay.width = compactOverlay ? "88%" : 56;
+    helpOverlay.width = compactOverlay ? "94%" : 78;
+    signalOverlay.width = compactOverlay ? "88%" : 56;
+    enableOverlay.width = compactOverlay ? "88%" : 56;
 
     renderAll();
   };
 
   const applyTheme = () => {
-    palette = getTheme(renderer.themeMode);
+    palette = getTheme(renderer.themeMode, theme);
     updateTooSmallState();
 
     root.backgroundColor = palette.bg;
@@ -2091,9 +2564,36 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
       renderDiscoveryOverlay();
     }
 
-    deleteOverlay.backgroundColor = palette.modal;
-    deleteTitle.fg = palette.red;
-    deleteMessage.fg = palette.active;
+    confirmOverlay.backgroundColor = palette.modal;
+    confirmTitle.fg = palette.red;
+    confirmMessage.fg = palette.active;
+
+    helpOverlay.backgroundColor = palette.modal;
+    helpTitle.fg = palette.accent;
+    helpBody.fg = palette.active;
+
+    signalOverlay.backgroundColor = palette.modal;
+    signalTitle.fg = palette.accent;
+    signalField.backgroundColor = palette.inputFocus;
+    signalInput.backgroundColor = palette.input;
+    signalInput.textColor = palette.active;
+    signalInput.focusedBackgroundColor = palette.inputFocus;
+    signalError.fg = palette.red;
+
+    enableOverlay.backgroundColor = palette.modal;
+    enableTitle.fg = palette.accent;
+    enableNames.fg = palette.muted;
+    enableField.backgroundColor = palette.inputFocus;
+    enableInput.backgroundColor = palette.input;
+    enableInput.textColor = palette.active;
+    enableInput.focusedBackgroundColor = palette.inputFocus;
+    enableError.fg = palette.red;
+
+    logSearchRow.backgroundColor = palette.input;
+    logSearchPrefix.fg = palette.amber;
+    logSearchInput.backgroundColor = palette.input;
+    logSearchInput.textColor = palette.active;
+    logSearchInput.focusedBackgroundColor = palette.inputFocus;
 
     lastLogVersion = -1;
     lastSelectedIndex = -1;
@@ -2121,7 +2621,7 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
       editOverlay.visible = true;
       addOverlay.visible = false;
       discoveryOverlay.visible = false;
-      deleteOverlay.visible = false;
+      confirmOverlay.visible = false;
       editError.content = "";
       editTextarea.initialValue = toml;
       renderer.requestRender();
@@ -2155,7 +2655,7 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
       addOverlay.visible = true;
       editOverlay.visible = false;
       discoveryOverlay.visible = false;
-      deleteOverlay.visible = false;
+      confirmOverlay.visible = false;
       addError.content = "";
       addFocusField = "name";
       addNameInput.value = "";
@@ -2208,19 +2708,104 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
       renderer.requestRender();
     },
 
-    showDeleteConfirm(name: string) {
+    showConfirm(title: string, message: string) {
+      overlayBg.visible = true;
+      confirmOverlay.visible = true;
+      editOverlay.visible = false;
+      addOverlay.visible = false;
+      discoveryOverlay.visible = false;
+      confirmTitle.content = title;
+      confirmMessage.content = `${message} (y/n)`;
+      renderer.requestRender();
+    },
+
+    hideConfirm() {
+      overlayBg.visible = false;
+      confirmOverlay.visible = false;
+      renderer.requestRender();
+    },
+
+    showHelp(sections: ShortcutSection[]) {
+      overlayBg.visible = true;
+      helpOverlay.visible = true;
+      editOverlay.visible = false;
+      addOverlay.visible = false;
+      discoveryOverlay.visible = false;
+      confirmOverlay.visible = false;
+      helpBody.content = sections
+        .map((section) => {
+          const bindings = section.shortcuts.map((shortcut) => `${shortcut.key} ${shortcut.label}`);
+          return `${section.title}\n  ${bindings.join("  ·  ")}`;
+        })
+        .join("\n\n");
+      renderer.requestRender();
+    },
+
+    hideHelp() {
+      overlayBg.visible = false;
+      helpOverlay.visible = false;
+      renderer.requestRender();
+    },
+
+    showSignalPrompt(name: string) {
+      overlayBg.visible = true;
+      signalOverlay.visible = true;
+      editOverlay.visible = false;
+      addOverlay.visible = false;
+      discoveryOverlay.visible = false;
+      confirmOverlay.visible = false;
+      signalTitle.content = `Send signal to ${name} (enter send, esc cancel)`;
+      signalError.content = "";
+      signalInput.value = "";
+      renderer.requestRender();
+      focusWhenVisible(signalOverlay, () => signalInput.focus());
+    },
+
+    hideSignalPrompt() {
+      overlayBg.visible = false;
+      signalOverlay.visible = false;
+      signalError.content = "";
+      signalInput.blur();
+      renderer.requestRender();
+    },
+
+    getSignalInput(): string {
+      return signalInput.value;
+    },
+
+    setSignalError(message: string) {
+      signalError.content = message;
+      renderer.requestRender();
+    },
+
+    showEnablePrompt(names: string[]) {
       overlayBg.visible = true;
-      deleteOverlay.visible = true;
+      enableOverlay.visible = true;
       editOverlay.visible = false;
       addOverlay.visible = false;
       discoveryOverlay.visible = false;
-      deleteMessage.content = `Delete "${name}"? (y/n)`;
+      confirmOverlay.visible = false;
+      enableNames.content = `Disabled: ${names.join(", ")}`;
+      enableError.content = "";
+      enableInput.value = names.length === 1 ? names[0] : "";
       renderer.requestRender();
+      focusWhenVisible(enableOverlay, () => enableInput.focus());
     },
 
-    hideDeleteConfirm() {
+    hideEnablePrompt() {
       overlayBg.visible = false;
-      deleteOverlay.visible = false;
+      enableOverlay.visible = false;
+      enableError.content = "";
+      enableInput.blur();
+      renderer.requestRender();
+    },
+
+    getEnableInput(): string {
+      return enableInput.value;
+    },
+
+    setEnableError(message: string) {
+      enableError.content = message;
       renderer.requestRender();
     },
 
@@ -2229,7 +2814,7 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
       discoveryOverlay.visible = true;
       editOverlay.visible = false;
       addOverlay.visible = false;
-      deleteOverlay.visible = false;
+      confirmOverlay.visible = false;
       discoveryError.content = "";
 
       unsubDiscoverySelection?.();
@@ -2320,6 +2905,85 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
       return logsFollowTail;
     },
 
+    showLogSearch() {
+      logSearchRow.visible = true;
+      logSearchInput.value = logSearchQuery;
+      renderer.requestRender();
+      focusWhenVisible(logSearchRow, () => logSearchInput.focus());
+    },
+
+    hideLogSearch() {
+      logSearchInput.blur();
+      logSearchRow.visible = logSearchQuery !== "";
+      renderer.requestRender();
+    },
+
+    getLogSearchInput(): string {
+      return logSearchInput.value;
+    },
+
+    setLogSearch(query: string) {
+      logSearchQuery = query;
+      logSearchInput.value = query;
+      if (logMatchesOnly) {
+        resetLogInteraction();
+      } else {
+        invalidateLogs();
+      }
+      renderAll();
+    },
+
+    moveLogMatch,
+
+    cycleLogStreamFilter() {
+      const order: LogStreamFilter[] = ["all", "stdout", "stderr"];
+      logStreamFilter = order[(order.indexOf(logStreamFilter) + 1) % order.length] ?? "all";
+      resetLogInteraction();
+      renderAll();
+      return logStreamFilter;
+    },
+
+    toggleLogMatchesOnly() {
+      logMatchesOnly = !logMatchesOnly;
+      resetLogInteraction();
+      renderAll();
+      return logMatchesOnly;
+    },
+
+    getActiveLogExport() {
+      const source = logSource === "docker" && dockerManager ? "docker" : "manifest";
+      const name =
+        source === "docker"
+          ? dockerManager?.getSelectedService()?.name
+          : manager.getSelectedView()?.name;
+      const buffer =
+        source === "docker"
+          ? (dockerManager?.getActiveLogBuffer() ?? null)
+          : (manager.getSelectedView()?.log ?? null);
+      if (!name || !buffer) return null;
+      return { name, text: buffer.getFullText() };
+    },
+
+    showNotice(message: string, isError = false) {
+      if (footerNoticeTimer) clearTimeout(footerNoticeTimer);
+      footerNotice = { content: message, fg: isError ? palette.red : palette.green };
+      footerNoticeTimer = setTimeout(() => {
+        footerNotice = null;
+        footerNoticeTimer = null;
+        rebuildFooter();
+        renderer.requestRender();
+      }, FOOTER_NOTICE_MS);
+      rebuildFooter();
+      renderer.requestRender();
+    },
+
+    toggleLogTimestamps() {
+      logTimestampsVisible = !logTimestampsVisible;
+      invalidateLogs();
+      renderAll();
+      return logTimestampsVisible;
+    },
+
     setLogsFollowTail(enabled: boolean) {
       logsFollowTail = enabled;
       if (logsFollowTail) {
@@ -2360,6 +3024,7 @@ export const buildUi = (opts: UiOptions): { teardown: () => void; controls: UiCo
   };
 
   const teardown = () => {
+    if (footerNoticeTimer) clearTimeout(footerNoticeTimer);
     renderer.off("theme_mode", applyTheme);
     renderer.off("resize", applyLayout);
     unsubManager();
@@ -0,0 +1,46 @@
+import { describe, expect, test } from "bun:test";
+import { mkdtemp, rm } from "node:fs/promises";
+import { tmpdir } from "node:os";
+import { join } from "node:path";
+import { UserConfigError, getUserConfigPath, loadUserConfig, mergeAppConfig } from "./user-config";
+
+describe("user config", () => {
+  test("resolves under XDG_CONFIG_HOME", () => {
+    expect(getUserConfigPath({ XDG_CONFIG_HOME: "/tmp/xdg" })).toBe("/tmp/xdg/stasium/config.toml");
+  });
+
+  test("treats a missing file as no config and rejects unknown tables", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-config-"));
+    const path = join(dir, "config.toml");
+
+    try {
+      expect(await loadUserConfig(path)).toBeUndefined();
+
+      await Bun.write(path, ["[app]", "confirm_stop = false", 'poll_interval = "2s"'].join("\n"));
+      expect(await loadUserConfig(path)).toMatchObject({
+        confirm_stop: false,
+        poll_interval: "2s",
+      });
+
+      await Bun.write(path, ["[vars]", 'PORT = "3000"'].join("\n"));
+      await expect(loadUserConfig(path)).rejects.toThrow(UserConfigError);
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+
+  test("lets manifest [app] values override the user config", () => {
+    const merged = mergeAppConfig(
+      {
+        confirm_stop: false,
+        log_lines: 500,
+        keys: { manifest: { stop: "z" }, logs: { clear: "C" } },
+      },
+      { log_lines: 100, keys: { manifest: { start: "b" } } },
+    );
+
+    expect(merged?.confirm_stop).toBe(false);
+    expect(merged?.log_lines).toBe(100);
+    expect(merged?.keys).toEqual({ manifest: { start: "b" }, logs: { clear: "C" } });
+  });
+});
@@ -0,0 +1,67 @@
+import { homedir } from "node:os";
+import { join } from "node:path";
+import { normalizeApp } from "./manifest";
+import { getErrorMessage } from "./shared";
+import type { AppConfig, AppKeysConfig } from "./types";
+
+export class UserConfigError extends Error {
+  constructor(message: string) {
+    super(message);
+    this.name = "UserConfigError";
+  }
+}
+
+export const getUserConfigPath = (env: Record<string, string | undefined> = process.env): string =>
+  join(env.XDG_CONFIG_HOME || join(homedir(), ".config"), "stasium", "config.toml");
+
+// The user config holds a single [app] table with the same keys as the manifest's. A missing
+// file is not an error: every setting then keeps its built-in default.
+export const loadUserConfig = async (
+  path: string = getUserConfigPath(),
+): Promise<AppConfig | undefined> => {
+  const file = Bun.file(path);
+  if (!(await file.exists())) return undefined;
+
+  let parsed: Record<string, unknown>;
+  try {
+    parsed = Bun.TOML.parse(await file.text()) as Record<string, unknown>;
+  } catch (error) {
+    throw new UserConfigError(`${path}: invalid TOML: ${getErrorMessage(error)}`);
+  }
+
+  const unknownKeys = Object.keys(parsed).filter((key) => key !== "app");
+  if (unknownKeys.length > 0) {
+    throw new UserConfigError(`${path}: unknown keys: ${unknownKeys.join(", ")}`);
+  }
+
+  try {
+    return normalizeApp(parsed.app);
+  } catch (error) {
+    throw new UserConfigError(`${path}: ${getErrorMessage(error)}`);
+  }
+};
+
+// A panel's [app.keys] table in the manifest replaces the user's table for that panel rather
+// than merging into it, so two individually valid keymaps cannot combine into a conflict.
+const mergeKeys = (defaults?: AppKeysConfig, keys?: AppKeysConfig): AppKeysConfig | undefined => {
+  if (!defaults) return keys;
+  if (!keys) return defaults;
+  return { ...defaults, ...keys };
+};
+
+// Manifest [app] values win over the user config, which wins over the built-in defaults.
+export const mergeAppConfig = (defaults?: AppConfig, app?: AppConfig): AppConfig | undefined => {
+  if (!defaults) return app;
+  if (!app) return defaults;
+  return {
+    docker: app.docker ?? defaults.docker,
+    confirm_stop: app.confirm_stop ?? defaults.confirm_stop,
+    log_lines: app.log_lines ?? defaults.log_lines,
+    resource_history: app.resource_history ?? defaults.resource_history,
+    log_timestamps: app.log_timestamps ?? defaults.log_timestamps,
+    metrics: app.metrics ?? defaults.metrics,
+    poll_interval: app.poll_interval ?? defaults.poll_interval,
+    keys: mergeKeys(defaults.keys, app.keys),
+    theme: app.theme ?? defaults.theme,
+  };
+};
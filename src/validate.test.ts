@@ -0,0 +1,38 @@
+import { describe, expect, test } from "bun:test";
+import { chmod, mkdtemp, rm } from "node:fs/promises";
+import { tmpdir } from "node:os";
+import { join } from "node:path";
+import type { Manifest } from "./types";
+import { findUnresolvableCommands } from "./validate";
+
+describe("strict manifest validation", () => {
+  test("reports commands that are missing from PATH or disk", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "stasium-validate-"));
+    const binDir = join(dir, "bin");
+
+    try {
+      await Bun.write(join(binDir, "serve"), "#!/bin/sh\n");
+      await chmod(join(binDir, "serve"), 0o755);
+      await Bun.write(join(dir, "scripts", "dev.sh"), "#!/bin/sh\n");
+
+      const manifest: Manifest = {
+        path: join(dir, "stasium.toml"),
+        vars: { tool: "serve" },
+        warnings: [],
+        services: [
+          { name: "api", command: ["${tool}", "--port", "3000"] },
+          { name: "dev", command: "./scripts/dev.sh" },
+          { name: "worker", command: ["stasium-missing-binary"] },
+          { name: "jobs", command: "./scripts/jobs.sh" },
+        ],
+      };
+
+      expect(await findUnresolvableCommands(manifest, binDir)).toEqual([
+        "service worker: command not found on PATH: stasium-missing-binary",
+        "service jobs: command not found: ./scripts/jobs.sh",
+      ]);
+    } finally {
+      await rm(dir, { recursive: true, force: true });
+    }
+  });
+});
@@ -0,0 +1,54 @@
+import { stat } from "node:fs/promises";
+import { dirname, isAbsolute, resolve } from "node:path";
+import { normalizeCommand } from "./command";
+import { createInterpolationContext, interpolateService } from "./interpolate";
+import { getManifestVars } from "./manifest";
+import { getErrorMessage } from "./shared";
+import type { Manifest } from "./types";
+
+const isRegularFile = async (path: string): Promise<boolean> => {
+  try {
+    const info = await stat(path);
+    return info.isFile();
+  } catch {
+    return false;
+  }
+};
+
+// Strict checks depend on the local machine, so they stay out of loadManifest and only run
+// when asked for. Paths containing a slash are checked on disk, bare names against PATH.
+export const findUnresolvableCommands = async (
+  manifest: Manifest,
+  path: string = process.env.PATH ?? "",
+): Promise<string[]> => {
+  const root = dirname(manifest.path);
+  const context = createInterpolationContext(root, getManifestVars(manifest));
+  const problems: string[] = [];
+
+  for (const config of manifest.services) {
+    const { service } = interpolateService(config, context);
+    let executable: string | undefined;
+    try {
+      executable = normalizeCommand(service.command)[0];
+    } catch (error) {
+      problems.push(`service ${service.name}: ${getErrorMessage(error)}`);
+      continue;
+    }
+    if (!executable) continue;
+
+    const workingDir = resolve(root, service.working_dir ?? ".");
+    if (executable.includes("/")) {
+      const target = isAbsolute(executable) ? executable : resolve(workingDir, executable);
+      if (!(await isRegularFile(target))) {
+        problems.push(`service ${service.name}: command not found: ${executable}`);
+      }
+      continue;
+    }
+
+    if (!Bun.which(executable, { PATH: path, cwd: workingDir })) {
+      problems.push(`service ${service.name}: command not found on PATH: ${executable}`);
+    }
+  }
+
+  return problems;
+};